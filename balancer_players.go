@@ -0,0 +1,222 @@
+package superspatial
+
+import (
+	"time"
+
+	"github.com/ScottBrooks/sos"
+)
+
+// playerSession is a connected player's profile.
+type playerSession struct {
+	Profile PlayerProfile
+	// Only players who logged in with a player identity token are saved.  Bots and anonymous players get a profile for
+	// the session, but it's gone when they leave.
+	Persist bool
+	// Team sticks with the player across respawns, 0 until they're put on one.
+	Team int32
+	// Respawn is the score a dead player gets back with their next ship, nil while they have one.
+	Respawn *ScoreComponent
+	// AutoRespawn players(bots) get a new ship as soon as they die, without asking for one.
+	AutoRespawn bool
+}
+
+// pendingSpawn is a client that connected in the middle of a critical section.  We wait for the section to end so
+// we've seen all of their worker entity's components(PlayerClient in particular) before spawning them.
+type pendingSpawn struct {
+	ID         sos.EntityID
+	WorkerType string
+}
+
+func (bs *BalancerScene) OnCriticalSection(op sos.CriticalSectionOp) {
+	bs.ServerScene.OnCriticalSection(op)
+	if op.In {
+		return
+	}
+
+	if bs.Leader && bs.resync == nil {
+		bs.spawnPending()
+	}
+}
+
+// spawnPending spawns the clients that connected while we couldn't.
+func (bs *BalancerScene) spawnPending() {
+	pending := bs.pendingSpawns
+	bs.pendingSpawns = nil
+	for _, p := range pending {
+		bs.spawnClient(p.ID, p.WorkerType)
+	}
+}
+
+// spawnClient loads the profile for the client on worker entity ID and gives them a ship, and players a session.
+func (bs *BalancerScene) spawnClient(ID sos.EntityID, workerType string) {
+	workerID, ok := bs.Clients[ID]
+	if !ok {
+		// Disconnected before we got to them.
+		return
+	}
+	// Players with a session were here before we took over.
+	playing := bs.sessionFor(workerID) != 0
+	if workerType != "Bot" {
+		bs.createSession(workerID)
+	}
+	if ship := bs.shipOf(workerID); ship != 0 {
+		if _, ok := bs.Players[workerID]; !ok {
+			bs.loadPlayer(workerID, workerType, bs.PlayerClients[ID])
+			bs.Players[workerID].Team = bs.Entities[ship].Team.Team
+		}
+		return
+	}
+
+	bs.loadPlayer(workerID, workerType, bs.PlayerClients[ID])
+	if playing {
+		// They were destroyed before we took over, and get a new ship when they ask for one.
+		bs.Players[workerID].Respawn = &ScoreComponent{}
+		return
+	}
+	bs.Notify(NotificationComponent{Kind: NotifyJoin, Subject: bs.Players[workerID].Profile.Name})
+	bs.CreateClientShip(workerID, ScoreComponent{})
+}
+
+func (bs *BalancerScene) loadPlayer(workerID string, workerType string, pc *ImprobablePlayerClient) {
+	ID, stable := playerIdentity(workerID, pc)
+	name := workerID
+	if pc != nil && pc.PlayerIdentity.PlayerIdentifier != "" {
+		name = pc.PlayerIdentity.PlayerIdentifier
+	}
+
+	isBot := workerType == "Bot"
+	session := &playerSession{Profile: NewPlayerProfile(ID, name), Persist: !isBot && stable, AutoRespawn: isBot}
+	if session.Persist {
+		bs.loadProfile(session, ID)
+	}
+	log.Printf("Loaded player %s: %+v", workerID, session.Profile)
+
+	bs.Players[workerID] = session
+}
+
+// loadProfile replaces the session's profile with the one saved as ID, if there is one.
+func (bs *BalancerScene) loadProfile(session *playerSession, ID string) {
+	if bs.Profiles == nil {
+		return
+	}
+	profile, err := bs.Profiles.Load(ID)
+	switch err {
+	case nil:
+		session.Profile = profile
+	case ErrProfileNotFound:
+		log.Printf("New player: %s", ID)
+	default:
+		log.Printf("Error loading profile for %s, starting fresh: %v", ID, err)
+	}
+}
+
+func (bs *BalancerScene) savePlayer(workerID string) {
+	session, ok := bs.Players[workerID]
+	if !ok || !session.Persist || bs.Profiles == nil {
+		return
+	}
+
+	session.Profile.LastSeen = time.Now()
+	if err := bs.Profiles.Save(session.Profile); err != nil {
+		log.Printf("Error saving profile for %s: %v", workerID, err)
+	}
+}
+
+// playerConnected reports if the client worker workerID is still around.
+func (bs *BalancerScene) playerConnected(workerID string) bool {
+	for _, c := range bs.Clients {
+		if c == workerID {
+			return true
+		}
+	}
+	return false
+}
+
+// shipOf is the ship the client workerID is flying, 0 if they don't have one.
+func (bs *BalancerScene) shipOf(workerID string) sos.EntityID {
+	for _, e := range bs.Entities {
+		if e.Client == workerID {
+			return e.ID
+		}
+	}
+	return 0
+}
+
+// trackScore moves the kills from a score update onto the player's lifetime stats.
+func (bs *BalancerScene) trackScore(e *balancedEntity, score ScoreComponent) {
+	if session, ok := bs.Players[e.Client]; ok && score.Kills > e.Score.Kills {
+		session.Profile.AddKills(score.Kills - e.Score.Kills)
+		bs.savePlayer(e.Client)
+	}
+	e.Score = score
+}
+
+// trackAppearance saves the hull and name a player picked to their profile, so their next ship looks the same.
+func (bs *BalancerScene) trackAppearance(e *balancedEntity, appearance ShipAppearanceComponent) {
+	session, ok := bs.Players[e.Client]
+	if !ok {
+		return
+	}
+
+	changed := false
+	if ValidHull(appearance.Hull) && appearance.Hull != session.Profile.Color {
+		session.Profile.Color = appearance.Hull
+		changed = true
+	}
+	if name := CleanShipName(appearance.Name); name != "" && name != session.Profile.Name {
+		session.Profile.Name = name
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	bs.savePlayer(e.Client)
+	e.Player = session.Profile.Component()
+	bs.spatial.UpdateComponent(e.ID, cidPlayer, e.Player)
+	bs.updateLeaderboard()
+}
+
+// assignTeam puts the player on the smallest team, or keeps them on the one they're already on.
+func (bs *BalancerScene) assignTeam(workerID string) int32 {
+	if bs.Mode.Teams == 0 {
+		return 0
+	}
+	session, ok := bs.Players[workerID]
+	if !ok {
+		session = &playerSession{}
+	}
+	if session.Team > 0 && int(session.Team) <= bs.Mode.Teams {
+		return session.Team
+	}
+
+	counts := make([]int, bs.Mode.Teams)
+	for _, p := range bs.Players {
+		if p != session && p.Team > 0 && int(p.Team) <= bs.Mode.Teams {
+			counts[p.Team-1]++
+		}
+	}
+	session.Team = smallestTeam(counts)
+	return session.Team
+}
+
+// setGameMode switches mode and reshuffles everyone onto balanced teams.
+func (bs *BalancerScene) setGameMode(mode GameMode) {
+	if mode == bs.Mode {
+		return
+	}
+	log.Printf("Switching game mode from %s to %s", bs.Mode, mode)
+	bs.Mode = mode
+
+	for _, session := range bs.Players {
+		session.Team = 0
+	}
+	for _, e := range bs.Entities {
+		if e.Client == "" {
+			continue
+		}
+		e.Team = TeamComponent{bs.assignTeam(e.Client)}
+		bs.spatial.UpdateComponent(e.ID, cidTeam, e.Team)
+	}
+	bs.updateLeaderboard()
+}
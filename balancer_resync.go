@@ -0,0 +1,299 @@
+package superspatial
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/ScottBrooks/sos"
+)
+
+const (
+	// How long we give each entity query, and how many times we send one that fails before going without it.
+	resyncQueryTimeout = 10 * time.Second
+	resyncQueryTries   = 3
+	// Without entity queries, how long entities have to stop turning up before we take it we've seen everything
+	// that was there when we took over.
+	resyncSettle = 2 * time.Second
+)
+
+// ServerWorkerComponent is on the entity holding a server worker's interest, so a restarted balancer can tell whose
+// it is.
+type ServerWorkerComponent struct {
+	WorkerID string
+}
+
+// resync is the balancer catching up with a deployment that was running before it started.  We query for everything
+// we keep track of, and it's done once the results are in.  Until then we don't spawn ships or create server
+// entities, so we don't make duplicates of ones that already exist.
+type resync struct {
+	// Queries are the entity queries still out, by request ID.  It's nil when the runtime can't run them, then we
+	// only have our interest to go on, and wait for entities to stop turning up.
+	Queries map[sos.RequestID]resyncQuery
+	// LastSeen is when the last entity turned up.
+	LastSeen time.Time
+}
+
+type resyncQuery struct {
+	Query QBIQuery
+	Tries int
+}
+
+// settled reports if we've seen everything there is to see.
+func (r *resync) settled(now time.Time) bool {
+	if r.Queries != nil {
+		return len(r.Queries) == 0
+	}
+	return now.Sub(r.LastSeen) >= resyncSettle
+}
+
+// BalancerResyncSystem finishes a resync once it has settled.
+type BalancerResyncSystem struct {
+	BS *BalancerScene
+}
+
+func (*BalancerResyncSystem) Remove(ecs.BasicEntity) {}
+func (brs *BalancerResyncSystem) Update(dt float32) {
+	brs.BS.checkResync(time.Now())
+}
+
+// startResync queries for the entities from before we took over.  Followers have already seen most of them.
+func (bs *BalancerScene) startResync() {
+	bs.resync = &resync{LastSeen: time.Now()}
+	if _, ok := interface{}(bs.spatial).(entityQuerier); !ok {
+		log.Printf("Unable to query entities, resyncing once they stop turning up")
+		return
+	}
+	bs.resync.Queries = map[sos.RequestID]resyncQuery{}
+	for _, q := range balancerQueries() {
+		bs.sendResyncQuery(resyncQuery{Query: q})
+	}
+}
+
+func (bs *BalancerScene) sendResyncQuery(rq resyncQuery) {
+	rq.Tries++
+	RID := interface{}(bs.spatial).(entityQuerier).SendEntityQuery(rq.Query, uint32(resyncQueryTimeout/time.Millisecond))
+	bs.resync.Queries[RID] = rq
+}
+
+// resyncQueried takes in the results of one of our queries, sending it again if it failed.
+func (bs *BalancerScene) resyncQueried(r entityQueryResult) {
+	if bs.resync == nil {
+		return
+	}
+	rq, ok := bs.resync.Queries[r.RequestID()]
+	if !ok {
+		return
+	}
+	delete(bs.resync.Queries, r.RequestID())
+
+	if r.StatusCode() != statusSuccess {
+		if rq.Tries < resyncQueryTries {
+			log.Printf("Resync query failed(%d), trying again: %s", r.StatusCode(), r.Message())
+			bs.sendResyncQuery(rq)
+		} else {
+			log.Printf("Resync query failed(%d) %d times, going with what we've seen: %s", r.StatusCode(), rq.Tries, r.Message())
+		}
+		return
+	}
+	bs.resyncEntities(r.Entities())
+}
+
+// resyncEntities takes in entities from a query as if they'd come in through our interest.  The ones we've already
+// seen are up to date.
+func (bs *BalancerScene) resyncEntities(entities map[sos.EntityID]map[sos.ComponentID]interface{}) {
+	for ID, components := range entities {
+		if _, ok := bs.Entities[ID]; ok {
+			continue
+		}
+		bs.OnAddEntity(sos.AddEntityOp{ID: ID})
+		for CID, c := range components {
+			bs.OnAddComponent(sos.AddComponentOp{ID: ID, CID: CID, Component: c})
+		}
+	}
+}
+
+// checkResync finishes the resync once it has settled.
+func (bs *BalancerScene) checkResync(now time.Time) {
+	if bs.resync == nil || bs.InCritical || !bs.resync.settled(now) {
+		return
+	}
+	bs.finishResync()
+}
+
+// finishResync rebuilds Workers from the server entities we've seen, then deals with everyone who connected while
+// we weren't leading.
+func (bs *BalancerScene) finishResync() {
+	bs.resync = nil
+
+	// Server entities outlive their workers when there's no leader around to delete them.
+	live := map[sos.EntityID]string{}
+	for ID, workerID := range bs.serverEntities {
+		if bs.serverEntity(workerID) == 0 {
+			log.Printf("Deleting server entity %d left by %s, which is gone", ID, workerID)
+			bs.spatial.Delete(ID)
+			continue
+		}
+		live[ID] = workerID
+	}
+
+	// The layout saved on the balancer entity is the one servers are using, stick with it if it covers them all.
+	workers, keepLayout := restoreWorkers(bs.State, live)
+	if !keepLayout {
+		workers = resyncWorkers(live)
+	}
+	bs.Workers = nil
+	for _, w := range workers {
+		w.WorkerEntityID = bs.serverEntity(w.WorkerID)
+		w.Process = workerProcess(w.WorkerID)
+		bs.Workers = append(bs.Workers, w)
+	}
+
+	for ID, e := range bs.Entities {
+		// Everything the balancers write, snapshot entities included, is written by whoever leads.
+		bs.claimEntity(e, bs.State.Leader)
+		if !keepLayout {
+			// Worker components point into the old layout, everyone gets assigned again.
+			e.Worker.WorkerID = -1
+			continue
+		}
+		if e.Worker.WorkerID < 0 {
+			e.Worker.WorkerID = bs.workerIndex(bs.State.Assignments[ID])
+		}
+	}
+	log.Printf("Resynced %d entities, %d clients and %d server workers", len(bs.Entities), len(bs.Clients), len(bs.Workers))
+
+	if len(bs.Workers) > 0 {
+		bs.TargetWorkerCount = len(bs.Workers)
+		if keepLayout {
+			bs.TargetWorkerCount = int(bs.State.TargetWorkerCount)
+			bs.WorkersAdjusting = bs.State.WorkersAdjusting
+		} else {
+			bs.rebalanceAuthority()
+		}
+		bs.checkEntityBounds()
+	}
+	bs.updateLeaderboard()
+	bs.stateDirty = true
+
+	// Servers that connected without an entity of their own get one now.
+	for ID, workerID := range bs.servers {
+		if !bs.hasWorker(workerID) {
+			bs.addServerWorker(ID, workerID)
+		}
+	}
+	for _, ID := range staleStandbys(bs.standbys, bs.balancers) {
+		log.Printf("Deleting standby entity %d left by a balancer that's gone", ID)
+		bs.spatial.Delete(ID)
+	}
+
+	bots := 0
+	for _, p := range bs.pendingSpawns {
+		if _, ok := bs.Clients[p.ID]; ok && p.WorkerType == "Bot" {
+			bots++
+		}
+	}
+	if !bs.InCritical {
+		bs.spawnPending()
+	}
+	bs.updateWorkerProcesses()
+
+	// Bots the last leader started are still playing, we only start our own if there aren't any.
+	if bs.botFlag != "" {
+		if bots == 0 {
+			bs.OnFlagUpdate(sos.FlagUpdateOp{Key: "NUM_BOTS", Value: bs.botFlag})
+		} else {
+			log.Printf("Leaving the %d bots already running be", bots)
+		}
+		bs.botFlag = ""
+	}
+}
+
+// addServerWorker creates the entity holding a newly connected server worker's interest.  Workers we already know
+// about from a resync keep the one they've got.
+func (bs *BalancerScene) addServerWorker(ID sos.EntityID, workerID string) {
+	bs.WorkersAdjusting = false
+	bs.stateDirty = true
+	for i, w := range bs.Workers {
+		if w.WorkerID == workerID {
+			bs.Workers[i].WorkerEntityID = ID
+			return
+		}
+	}
+
+	ent := NewServerWorker(workerID)
+	ent.ACL, _ = claimACL(ent.ACL, "", bs.ServerScene.WorkerID)
+	reqID := bs.spatial.CreateEntity(ent)
+	bs.OnCreateFunc[reqID] = func(entID sos.EntityID) {
+		ent.ID = entID
+		log.Printf("Create complete")
+
+		bs.Workers = append(bs.Workers, balancedWorker{WorkerID: workerID, WorkerEntityID: ID, ID: entID, Process: workerProcess(workerID)})
+		bs.stateDirty = true
+		bs.updateWorkerProcesses()
+
+		bs.checkEntityBounds()
+	}
+}
+
+// serverEntity is the worker entity of the connected server worker workerID, 0 if it isn't connected.
+func (bs *BalancerScene) serverEntity(workerID string) sos.EntityID {
+	for ID, w := range bs.servers {
+		if w == workerID {
+			return ID
+		}
+	}
+	return 0
+}
+
+// hasWorker reports if the server worker workerID is one of Workers.
+func (bs *BalancerScene) hasWorker(workerID string) bool {
+	for _, w := range bs.Workers {
+		if w.WorkerID == workerID {
+			return true
+		}
+	}
+	return false
+}
+
+// resyncWorkers rebuilds the server workers from their entities, in the order they were created so they keep the
+// same cells.
+func resyncWorkers(servers map[sos.EntityID]string) []balancedWorker {
+	workers := []balancedWorker{}
+	for ID, workerID := range servers {
+		w := NewServerWorker(workerID)
+		w.ID = ID
+		workers = append(workers, w)
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].ID < workers[j].ID })
+	return workers
+}
+
+// aclWorker is the worker with sole write access to cid, if there is one.
+func aclWorker(acl ImprobableACL, cid uint32) string {
+	for _, as := range acl.ComponentWriteAcl[cid].AttributeSet {
+		for _, a := range as.Attribute {
+			if strings.HasPrefix(a, "workerId:") {
+				return strings.TrimPrefix(a, "workerId:")
+			}
+		}
+	}
+	return ""
+}
+
+// workerProcess finds the process we started for a server worker, they're named after their pid.
+func workerProcess(workerID string) *os.Process {
+	pid, err := strconv.Atoi(strings.TrimPrefix(workerID, "Server_"))
+	if err != nil {
+		log.Printf("Expected to be able to turn worker id into a pid: %+v", err)
+	}
+
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		log.Printf("Expected to be able to find process: %v", err)
+	}
+	return proc
+}
@@ -0,0 +1,78 @@
+package superspatial
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ScottBrooks/sos"
+)
+
+func shipACL(client string, server string) *ImprobableACL {
+	return &ImprobableACL{ComponentWriteAcl: map[uint32]WorkerRequirementSet{
+		cidPlayerInput: {[]WorkerAttributeSet{{[]string{"workerId:" + client}}}},
+		cidShip:        {[]WorkerAttributeSet{{[]string{"workerId:" + server}}}},
+		cidACL:         {[]WorkerAttributeSet{{[]string{"balancer"}}}},
+	}}
+}
+
+func TestAclWorker(t *testing.T) {
+	acl := *shipACL("client1", "Server_12")
+	if got := aclWorker(acl, cidPlayerInput); got != "client1" {
+		t.Errorf("input: got %q", got)
+	}
+	if got := aclWorker(acl, cidShip); got != "Server_12" {
+		t.Errorf("ship: got %q", got)
+	}
+	if got := aclWorker(acl, cidACL); got != "" {
+		t.Errorf("acl: got %q, want nobody", got)
+	}
+}
+
+func TestResyncWorkers(t *testing.T) {
+	servers := map[sos.EntityID]string{30: "Server_2", 20: "Server_1"}
+	got := []string{}
+	for _, w := range resyncWorkers(servers) {
+		got = append(got, w.WorkerID)
+	}
+	if want := []string{"Server_1", "Server_2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCheckResync(t *testing.T) {
+	now := time.Now()
+	bs := BalancerScene{resync: &resync{LastSeen: now}}
+	bs.checkResync(now.Add(resyncSettle / 2))
+	if bs.resync == nil {
+		t.Fatal("finished before it settled")
+	}
+	bs.InCritical = true
+	bs.checkResync(now.Add(2 * resyncSettle))
+	if bs.resync == nil {
+		t.Fatal("finished in a critical section")
+	}
+
+	// With queries out, we wait on their results however long it's been.
+	bs = BalancerScene{resync: &resync{LastSeen: now, Queries: map[sos.RequestID]resyncQuery{1: {}}}}
+	bs.checkResync(now.Add(time.Hour))
+	if bs.resync == nil {
+		t.Fatal("finished with a query still out")
+	}
+}
+
+func TestResyncEntities(t *testing.T) {
+	bs := BalancerScene{Entities: map[sos.EntityID]*balancedEntity{
+		1: {ID: 1, Team: TeamComponent{Team: 2}},
+	}}
+	bs.resyncEntities(map[sos.EntityID]map[sos.ComponentID]interface{}{
+		1: {cidTeam: &TeamComponent{Team: 1}},
+		2: {cidTeam: &TeamComponent{Team: 1}},
+	})
+	if len(bs.Entities) != 2 || bs.Entities[2].Team.Team != 1 || bs.Entities[2].Worker.WorkerID != -1 {
+		t.Fatalf("queried entity: got %+v", bs.Entities[2])
+	}
+	if bs.Entities[1].Team.Team != 2 {
+		t.Errorf("overwrote an entity we'd already seen: %+v", bs.Entities[1])
+	}
+}
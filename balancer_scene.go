@@ -1,26 +1,28 @@
 package superspatial
 
 import (
+	"fmt"
 	"math"
-	"math/rand"
 	"os"
 	"os/exec"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/EngoEngine/ecs"
 	"github.com/EngoEngine/engo"
 	"github.com/ScottBrooks/sos"
-	"github.com/go-gl/mathgl/mgl32"
 )
 
 type balancedWorker struct {
 	ID sos.EntityID
 
-	ACL      ImprobableACL      `sos:"50"`
-	Pos      ImprobablePosition `sos:"54"`
-	Meta     ImprobableMetadata `sos:"53"`
-	Interest ImprobableInterest `sos:"58"`
+	ACL      ImprobableACL         `sos:"50"`
+	Pos      ImprobablePosition    `sos:"54"`
+	Meta     ImprobableMetadata    `sos:"53"`
+	Interest ImprobableInterest    `sos:"58"`
+	Server   ServerWorkerComponent `sos:"1017"`
 
 	WorkerID       string
 	WorkerEntityID sos.EntityID
@@ -38,8 +40,15 @@ type balancedEntity struct {
 	ACL    ImprobableACL      `sos:"50"`
 	Pos    ImprobablePosition `sos:"54"`
 	Worker WorkerComponent    `sos:"1005"`
+	Score  ScoreComponent     `sos:"1007"`
+	Player PlayerComponent    `sos:"1009"`
+	Team   TeamComponent      `sos:"1011"`
+	// Obstacle is set on obstacles, so we don't spawn ships inside them.
+	Obstacle ObstacleComponent `sos:"1019"`
 
 	Client string
+	// Seam is the seam strips we last added to a player's interest.
+	Seam []QBIConstraint
 }
 
 type BalancerScene struct {
@@ -51,9 +60,58 @@ type BalancerScene struct {
 	Workers           []balancedWorker
 	Entities          map[sos.EntityID]*balancedEntity
 
-	BotProcesses    []*os.Process
+	// BotProcesses are the bots we've started, keyed by the brain they're running.
+	BotProcesses    map[string][]*os.Process
 	WorkerProcesses []*os.Process
 	Clients         map[sos.EntityID]string
+	PlayerClients   map[sos.EntityID]*ImprobablePlayerClient
+
+	// Profiles persists players between sessions, when nil nothing is saved.
+	Profiles      ProfileStore
+	Players       map[string]*playerSession
+	pendingSpawns []pendingSpawn
+	// sessions are the players' session entities, by the client worker they belong to.
+	sessions map[sos.EntityID]string
+	// Leader is set while we have authority over the Balancer component, followers just keep track of things.
+	Leader bool
+	// botFlag is NUM_BOTS, held on to until we're leading.
+	botFlag string
+	// balancers are the other balancer workers, by worker entity.
+	balancers map[sos.EntityID]string
+	// servers are the server workers that are connected, by worker entity.  serverEntities are the entities holding
+	// server workers' interest, which outlive the worker if it goes while there's no leader to clean up after it.
+	servers        map[sos.EntityID]string
+	serverEntities map[sos.EntityID]string
+	// standbys are the balancers' standby entities, by the balancer they belong to.
+	standbys map[sos.EntityID]string
+
+	// BalancerID is our own entity, State is what we last saved to it.  stateDirty is set when Workers, the scaling
+	// or an assignment changes, and the state needs saving again.
+	BalancerID sos.EntityID
+	State      BalancerComponent
+	stateDirty bool
+	// resync is set while we're catching up with entities from before we started.
+	resync *resync
+
+	// Mode is the GAME_MODE flag, picking free-for-all or how many teams to split players into.
+	Mode GameMode
+
+	// MatchID is the match entity, the match itself is ServerScene.Match.
+	MatchID    sos.EntityID
+	MatchRules MatchRules
+
+	LeaderboardID   sos.EntityID
+	LeaderboardSize int
+	Leaderboard     LeaderboardComponent
+
+	// ChatID is the entity global chat goes out on.
+	ChatID sos.EntityID
+	// ChatFilters are run over every chat message, in order.
+	ChatFilters []ChatFilter
+	chatLimiter chatLimiter
+	chatSeq     int64
+	// chatRecent are the latest messages on each chat component, by entity.
+	chatRecent map[sos.EntityID][]ChatMessage
 }
 
 func (*BalancerScene) Preload() {}
@@ -62,14 +120,46 @@ func (bs *BalancerScene) Setup(u engo.Updater) {
 	log = log.WithField("worker", bs.ServerScene.WorkerType())
 	sos.SilenceLogs()
 
+	if bs.ServerScene.WorkerID == "" {
+		// The same id sos would pick, we need to know it to give our standby entity to ourselves.
+		bs.ServerScene.WorkerID = fmt.Sprintf("%s_%d", bs.ServerScene.WorkerType(), os.Getpid())
+	}
 	bs.spatial = sos.NewSpatialSystem(bs, bs.ServerScene.Host, bs.ServerScene.Port, bs.ServerScene.WorkerID, nil)
 	bs.Entities = map[sos.EntityID]*balancedEntity{}
 	bs.Clients = map[sos.EntityID]string{}
+	bs.PlayerClients = map[sos.EntityID]*ImprobablePlayerClient{}
+	bs.Players = map[string]*playerSession{}
+	bs.balancers = map[sos.EntityID]string{}
+	bs.sessions = map[sos.EntityID]string{}
+	bs.servers = map[sos.EntityID]string{}
+	bs.serverEntities = map[sos.EntityID]string{}
+	bs.standbys = map[sos.EntityID]string{}
+	bs.chatRecent = map[sos.EntityID][]ChatMessage{}
+	bs.BotProcesses = map[string][]*os.Process{}
 	bs.ServerScene.OnCreateFunc = map[sos.RequestID]func(ID sos.EntityID){}
+	bs.ServerScene.Commands = Commands{}
+	bs.setupWorld(bs.WorldBounds)
+
+	// Whoever gets the Balancer component leads, everyone makes a standby entity so they can follow along until then.
+	bs.spatial.CreateEntity(newBalancerStandby(bs.ServerScene.WorkerID))
+	if bs.MatchRules == (MatchRules{}) {
+		bs.MatchRules = defaultMatchRules
+	}
 
 	log.Printf("New spatialsystem")
 
 	w.AddSystem(&SpatialPumpSystem{&bs.ServerScene})
+	w.AddSystem(&MatchSystem{bs})
+	w.AddSystem(&BalancerStateSystem{bs})
+	w.AddSystem(&BalancerResyncSystem{bs})
+
+	engo.Mailbox.Listen(DeleteEntityMessage{}.Type(), func(msg engo.Message) {
+		dem, ok := msg.(DeleteEntityMessage)
+		if !ok {
+			return
+		}
+		bs.spatial.Delete(dem.ID)
+	})
 }
 func (*BalancerScene) Type() string { return "Balancer" }
 
@@ -78,9 +168,34 @@ func (bs *BalancerScene) OnAuthorityChange(op sos.AuthorityChangeOp) {
 	if op.Authority == 1 && op.CID == cidACL {
 		bs.checkEntityBounds()
 	}
+	if op.Authority == 1 && op.CID == cidLeaderboard {
+		bs.LeaderboardID = op.ID
+		bs.updateLeaderboard()
+	}
+	if op.Authority == 1 && op.CID == cidMatch {
+		bs.MatchID = op.ID
+	}
+	if op.Authority == 1 && op.CID == cidGlobalChat {
+		bs.ChatID = op.ID
+	}
+	// A session can't look at itself until it exists, so its interest is filled in once we can write it.
+	if _, ok := bs.sessions[op.ID]; ok && op.Authority == 1 && op.CID == cidInterest {
+		bs.spatial.UpdateComponent(op.ID, cidInterest, sessionInterest(op.ID))
+	}
+	if op.CID == cidBalancer {
+		if op.Authority == 1 {
+			bs.BalancerID = op.ID
+			bs.takeOver()
+		} else {
+			bs.stepDown()
+		}
+	}
 }
 
 func (bs *BalancerScene) OnAddEntity(op sos.AddEntityOp) {
+	if bs.resync != nil {
+		bs.resync.LastSeen = time.Now()
+	}
 	if bs.Entities[op.ID] == nil {
 		bs.Entities[op.ID] = &balancedEntity{ID: op.ID, Worker: WorkerComponent{-1}}
 	} else {
@@ -88,6 +203,15 @@ func (bs *BalancerScene) OnAddEntity(op sos.AddEntityOp) {
 	}
 }
 
+func (bs *BalancerScene) OnEntityQuery(op sos.EntityQueryOp) {
+	r, ok := interface{}(op).(entityQueryResult)
+	if !ok {
+		bs.ServerScene.OnEntityQuery(op)
+		return
+	}
+	bs.resyncQueried(r)
+}
+
 func (bs *BalancerScene) OnAddComponent(op sos.AddComponentOp) {
 	log.Printf("OnAddComponent: %+v, %+v", op, op.Component)
 	switch c := op.Component.(type) {
@@ -98,42 +222,97 @@ func (bs *BalancerScene) OnAddComponent(op sos.AddComponentOp) {
 			bs.Clients[op.ID] = c.WorkerID
 
 			bs.updateWorkerProcesses()
-			bs.CreateClientShip(c.WorkerID)
+			if !bs.Leader || bs.InCritical || bs.resync != nil {
+				bs.pendingSpawns = append(bs.pendingSpawns, pendingSpawn{ID: op.ID, WorkerType: c.WorkerType})
+			} else {
+				bs.spawnClient(op.ID, c.WorkerType)
+			}
 		case "Server":
-			bs.WorkersAdjusting = false
-
-			ent := NewServerWorker()
-			reqID := bs.spatial.CreateEntity(ent)
-			bs.OnCreateFunc[reqID] = func(ID sos.EntityID) {
-				ent.ID = ID
-				log.Printf("Create complete")
-
-				pid, err := strconv.Atoi(strings.TrimPrefix(c.WorkerID, "Server_"))
-				if err != nil {
-					log.Printf("Expected to be able to turn worker id into a pid: %+v", err)
-				}
-
-				proc, err := os.FindProcess(pid)
-				if err != nil {
-					log.Printf("Expected to be able to find process: %v", err)
-				}
-				bs.Workers = append(bs.Workers, balancedWorker{WorkerID: c.WorkerID, WorkerEntityID: op.ID, ID: ID, Process: proc})
-				bs.updateWorkerProcesses()
-
-				bs.checkEntityBounds()
+			bs.servers[op.ID] = c.WorkerID
+			if bs.Leader && bs.resync == nil {
+				bs.addServerWorker(op.ID, c.WorkerID)
 			}
+		case "Balancer":
+			bs.balancers[op.ID] = c.WorkerID
 		}
+	case *ImprobablePlayerClient:
+		bs.PlayerClients[op.ID] = c
+	case *SessionComponent:
+		bs.sessions[op.ID] = c.WorkerID
+	case *ServerWorkerComponent:
+		bs.serverEntities[op.ID] = c.WorkerID
+	case *BalancerStandbyComponent:
+		bs.standbys[op.ID] = c.WorkerID
+	case *ImprobablePosition:
+		bs.Entities[op.ID].Pos = *c
+	case *WorkerComponent:
+		bs.Entities[op.ID].Worker = *c
 	case *ImprobableACL:
-		bs.Entities[op.ID].ACL = *op.Component.(*ImprobableACL)
+		e := bs.Entities[op.ID]
+		e.ACL = *c
+		if e.Client == "" {
+			e.Client = aclWorker(e.ACL, cidPlayerInput)
+		}
+		// Entities servers create, like effects and notifications, start out written by the balancer layer.
+		if bs.Leader && bs.resync == nil {
+			bs.claimEntity(e, "")
+		}
+	case *PlayerComponent:
+		bs.Entities[op.ID].Player = *c
+	case *TeamComponent:
+		bs.Entities[op.ID].Team = *c
+	case *ObstacleComponent:
+		bs.Entities[op.ID].Obstacle = *c
+	case *ShipAppearanceComponent:
+		bs.trackAppearance(bs.Entities[op.ID], *c)
+	case *ScoreComponent:
+		bs.Entities[op.ID].Score = *c
+		bs.updateLeaderboard()
+	case *LeaderboardComponent:
+		bs.Leaderboard = *c
+	case *MatchComponent:
+		bs.Match = *c
+	case *ChatComponent:
+		bs.followChat(op.ID, c.Recent)
+	case *GlobalChatComponent:
+		bs.followChat(op.ID, c.Recent)
+	case *BalancerComponent:
+		bs.State = *c
+		if !bs.Leader {
+			bs.mirrorState(bs.State)
+		}
 	}
 }
 
 func (bs *BalancerScene) OnRemoveComponent(op sos.RemoveComponentOp) {
 
 	if op.CID == cidWorker {
+		delete(bs.servers, op.ID)
+		if workerID, ok := bs.balancers[op.ID]; ok {
+			delete(bs.balancers, op.ID)
+			if bs.Leader {
+				bs.removeStandby(workerID)
+			}
+		}
+
 		client, ok := bs.Clients[op.ID]
 		if ok {
+			if bs.Leader {
+				if session, ok := bs.Players[client]; ok {
+					bs.Notify(NotificationComponent{Kind: NotifyLeave, Subject: session.Profile.Name})
+				}
+				bs.savePlayer(client)
+			}
 			delete(bs.Clients, op.ID)
+			delete(bs.PlayerClients, op.ID)
+			delete(bs.Players, client)
+		}
+		if !bs.Leader {
+			return
+		}
+
+		if session := bs.sessionFor(client); ok && session != 0 {
+			bs.spatial.Delete(session)
 		}
 
 		toDelete := -1
@@ -146,6 +325,7 @@ func (bs *BalancerScene) OnRemoveComponent(op sos.RemoveComponentOp) {
 			log.Printf("Deleting worker:%+v", bs.Workers[toDelete])
 			bs.spatial.Delete(bs.Workers[toDelete].ID)
 			bs.Workers = append(bs.Workers[:toDelete], bs.Workers[toDelete+1:]...)
+			bs.stateDirty = true
 		}
 		bs.updateWorkerProcesses()
 
@@ -169,12 +349,29 @@ func (bs *BalancerScene) OnRemoveComponent(op sos.RemoveComponentOp) {
 }
 
 func (bs *BalancerScene) OnRemoveEntity(op sos.RemoveEntityOp) {
+	delete(bs.sessions, op.ID)
+	delete(bs.serverEntities, op.ID)
+	delete(bs.standbys, op.ID)
 	if e := bs.Entities[op.ID]; e != nil {
-		if e.Client != "" {
-			bs.CreateClientShip(e.Client)
+		// Only respawn players that are still connected, not ones we're cleaning up after.
+		if bs.Leader && e.Client != "" && bs.playerConnected(e.Client) {
+			if session, ok := bs.Players[e.Client]; ok {
+				session.Profile.AddDeath()
+				bs.savePlayer(e.Client)
+			}
+
+			// Carry the score over to the new ship, counting this as a death.
+			score := e.Score
+			score.Deaths++
+			score.Streak = 0
+			bs.playerDied(e.Client, score)
 		}
 		log.Printf("Removing entity: %d %+v", op.ID, e)
 		delete(bs.Entities, op.ID)
+		if e.Worker.WorkerID >= 0 {
+			bs.stateDirty = true
+		}
+		bs.updateLeaderboard()
 	}
 
 }
@@ -200,6 +397,9 @@ func (bs *BalancerScene) OnCreateEntity(op sos.CreateEntityOp) {
 
 }
 func (bs *BalancerScene) OnComponentUpdate(op sos.ComponentUpdateOp) {
+	if bs.commandUpdate(op) {
+		return
+	}
 	switch op.CID {
 	case cidPosition:
 		pos, ok := op.Component.(*ImprobablePosition)
@@ -208,6 +408,7 @@ func (bs *BalancerScene) OnComponentUpdate(op sos.ComponentUpdateOp) {
 			//log.Printf("Component update for: %d", op.ID)
 			if ok {
 				ent.Pos = *pos
+				bs.updateSeamInterest(ent)
 			}
 			bs.checkEntityBounds()
 		}
@@ -220,6 +421,37 @@ func (bs *BalancerScene) OnComponentUpdate(op sos.ComponentUpdateOp) {
 				ent.ACL = *acl
 			}
 		}
+	case cidBalancer:
+		state, ok := op.Component.(*BalancerComponent)
+		if ok && !bs.Leader {
+			bs.State = *state
+			bs.mirrorState(bs.State)
+		}
+	case cidScore:
+		score, ok := op.Component.(*ScoreComponent)
+		if ok {
+			ent, ok := bs.Entities[op.ID]
+			if ok {
+				bs.trackScore(ent, *score)
+				bs.updateLeaderboard()
+			}
+		}
+	case cidShipAppearance:
+		appearance, ok := op.Component.(*ShipAppearanceComponent)
+		if ok {
+			ent, ok := bs.Entities[op.ID]
+			if ok {
+				bs.trackAppearance(ent, *appearance)
+			}
+		}
+	case cidChat:
+		if c, ok := op.Component.(*ChatComponent); ok {
+			bs.followChat(op.ID, c.Recent)
+		}
+	case cidGlobalChat:
+		if c, ok := op.Component.(*GlobalChatComponent); ok {
+			bs.followChat(op.ID, c.Recent)
+		}
 	}
 }
 
@@ -230,12 +462,13 @@ func aabbContains(aabb engo.AABB, pt Coordinates) bool {
 }
 func (bs *BalancerScene) adjustAcl(i int, e *balancedEntity, w balancedWorker) {
 	e.Worker.WorkerID = int32(i)
+	bs.stateDirty = true
 	bs.spatial.UpdateComponent(e.ID, cidWorkerBalancer, e.Worker)
 
 	workerID := "workerId:" + w.WorkerID
 
 	// Update our ACL entries that varry per worker.
-	for _, cid := range []uint32{cidShip, cidPosition, cidEffect} {
+	for _, cid := range []uint32{cidShip, cidPosition, cidEffect, cidScore, cidObstacle} {
 		if _, ok := e.ACL.ComponentWriteAcl[cid]; ok {
 			e.ACL.ComponentWriteAcl[cid] = WorkerRequirementSet{[]WorkerAttributeSet{{[]string{workerID}}}}
 		}
@@ -245,6 +478,9 @@ func (bs *BalancerScene) adjustAcl(i int, e *balancedEntity, w balancedWorker) {
 }
 
 func (bs *BalancerScene) checkEntityBounds() {
+	if !bs.Leader || bs.resync != nil {
+		return
+	}
 	for _, e := range bs.Entities {
 		needsAdjustment := true
 		if e.Worker.WorkerID >= 0 && int(e.Worker.WorkerID) < len(bs.Workers) {
@@ -267,28 +503,116 @@ func (bs *BalancerScene) checkEntityBounds() {
 
 func (bs *BalancerScene) OnFlagUpdate(op sos.FlagUpdateOp) {
 	log.Printf("Flag Update: %+v", op)
+	if op.Key == "WORLD_TOPOLOGY" {
+		bs.ServerScene.OnFlagUpdate(op)
+		// Workers and players near the edge need to start or stop looking across the seam.
+		if bs.Leader {
+			bs.rebalanceAuthority()
+			for _, e := range bs.Entities {
+				bs.updateSeamInterest(e)
+			}
+		}
+	}
+	if op.Key == "GAME_MODE" {
+		mode, err := ParseGameMode(op.Value)
+		if err != nil {
+			log.Printf("Error parsing game mode %s: %v", op.Value, err)
+			return
+		}
+		if !bs.Leader {
+			bs.Mode = mode
+			return
+		}
+		bs.setGameMode(mode)
+	}
+	if op.Key == "MATCH_TIME_LIMIT" || op.Key == "MATCH_SCORE_LIMIT" {
+		n, err := strconv.Atoi(op.Value)
+		if err != nil || n < 0 {
+			log.Printf("Error parsing %s %s: %v", op.Key, op.Value, err)
+			return
+		}
+		// Takes effect from the next match.
+		if op.Key == "MATCH_TIME_LIMIT" {
+			bs.MatchRules.TimeLimit = time.Duration(n) * time.Second
+		} else {
+			bs.MatchRules.ScoreLimit = int32(n)
+		}
+	}
 	if op.Key == "NUM_BOTS" {
-		target, err := strconv.Atoi(op.Value)
+		if !bs.Leader || bs.resync != nil {
+			bs.botFlag = op.Value
+			return
+		}
+		mix, err := parseBotMix(op.Value)
 		if err != nil {
-			log.Printf("Error converting %s to int: %v", op.Value, err)
+			log.Printf("Error parsing bot mix %s: %v", op.Value, err)
 			return
 		}
 
-		delta := target - len(bs.BotProcesses)
-		log.Printf("Bots delta: %d", delta)
-		if delta > 0 {
-			for i := 0; i < delta; i++ {
-				bs.startBot()
+		// Brains we're running that aren't in the new mix go to zero.
+		for brain := range bs.BotProcesses {
+			if _, ok := mix[brain]; !ok {
+				mix[brain] = 0
 			}
 		}
 
-		if delta < 0 {
-			for i := delta; i < 0; i++ {
-				// Kill off bots
-				bs.stopBot()
+		for brain, target := range mix {
+			delta := target - len(bs.BotProcesses[brain])
+			log.Printf("Bots delta for %s: %d", brain, delta)
+			if delta > 0 {
+				for i := 0; i < delta; i++ {
+					bs.startBot(brain)
+				}
 			}
+
+			if delta < 0 {
+				for i := delta; i < 0; i++ {
+					// Kill off bots
+					bs.stopBot(brain)
+				}
+			}
+		}
+	}
+}
+
+// parseBotMix turns a NUM_BOTS flag into a count per brain.  It is either a plain count of bots("8"), which fly with
+// whatever brain the BOT_BRAIN flag picks and are counted under "", or a list of brain:count
+// pairs("hunter:3,wanderer:5,bots/skirmisher.json:2").
+func parseBotMix(value string) (map[string]int, error) {
+	mix := map[string]int{}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return mix, nil
+	}
+
+	if count, err := strconv.Atoi(value); err == nil {
+		if count < 0 {
+			return nil, fmt.Errorf("Negative bot count: %d", count)
+		}
+		mix[""] = count
+		return mix, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		idx := strings.LastIndex(entry, ":")
+		if idx == -1 {
+			return nil, fmt.Errorf("Expected brain:count, got %s", entry)
+		}
+		brain := strings.TrimSpace(entry[:idx])
+		// Loading the brain catches typos in tree files now, rather than when a bot fails to start with it.
+		if _, err := NewBotBrain(brain); err != nil {
+			return nil, err
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(entry[idx+1:]))
+		if err != nil {
+			return nil, err
 		}
+		if count < 0 {
+			return nil, fmt.Errorf("Negative bot count for %s: %d", brain, count)
+		}
+		mix[brain] += count
 	}
+	return mix, nil
 }
 
 func calcRequiredWorkers(c int) int {
@@ -318,6 +642,9 @@ func calcRequiredWorkers(c int) int {
 }
 
 func (bs *BalancerScene) updateWorkerProcesses() {
+	if !bs.Leader || bs.resync != nil {
+		return
+	}
 	var numWorkers int
 	for _, w := range bs.Workers {
 		if !w.Killing {
@@ -334,6 +661,7 @@ func (bs *BalancerScene) updateWorkerProcesses() {
 	if reqWorkers > numWorkers && !bs.WorkersAdjusting {
 		log.Printf("We are requesting more workers: %d %d", reqWorkers, numWorkers)
 		bs.TargetWorkerCount = reqWorkers
+		bs.stateDirty = true
 		bs.startWorker()
 	}
 	if reqWorkers < numWorkers && !bs.WorkersAdjusting {
@@ -342,24 +670,31 @@ func (bs *BalancerScene) updateWorkerProcesses() {
 	}
 }
 
-func (bs *BalancerScene) CreateClientShip(WorkerID string) {
+func (bs *BalancerScene) CreateClientShip(WorkerID string, score ScoreComponent) {
 	// Create entity,
 	log.Printf("Creating client entity: %s", WorkerID)
-	spawnPoint := mgl32.Vec2{rand.Float32() * bs.WorldBounds.Max.X, rand.Float32() * bs.WorldBounds.Max.Y}
-	ent := NewShip(spawnPoint, WorkerID)
+	ent := NewShip(bs.spawnPoint(), WorkerID)
+	ent.Ship.ProtectedUntil = unixMillis(time.Now().Add(spawnProtection))
+	ent.Score = score
+	if session, ok := bs.Players[WorkerID]; ok {
+		ent.Player = session.Profile.Component()
+		ent.Appearance = ShipAppearanceComponent{Hull: session.Profile.Color, Name: session.Profile.Name}
+	}
+	ent.Team = TeamComponent{bs.assignTeam(WorkerID)}
+	ent.ACL, _ = claimACL(ent.ACL, "", bs.ServerScene.WorkerID)
 
 	reqID := bs.spatial.CreateEntity(ent)
 	bs.OnCreateFunc[reqID] = func(ID sos.EntityID) {
 		ent.ID = ID
 
-		bs.Entities[ID] = &balancedEntity{ID: ID, Worker: WorkerComponent{-1}, Client: WorkerID, ACL: ent.ACL}
+		bs.Entities[ID] = &balancedEntity{ID: ID, Worker: WorkerComponent{-1}, Client: WorkerID, ACL: ent.ACL, Score: ent.Score, Player: ent.Player, Team: ent.Team}
 		log.Printf("Entity: %+v", bs.Entities[ID])
 	}
 
 }
 
 func (bs *BalancerScene) startWorker() {
-	cmd := exec.Command("./server", "-host", bs.ServerScene.Host, "-port", strconv.Itoa(bs.ServerScene.Port))
+	cmd := exec.Command("./server", append([]string{"-host", bs.ServerScene.Host, "-port", strconv.Itoa(bs.ServerScene.Port)}, bs.topologyArgs()...)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -368,6 +703,7 @@ func (bs *BalancerScene) startWorker() {
 		log.Printf("Error starting worker: %+v", err)
 	}
 	bs.WorkersAdjusting = true
+	bs.stateDirty = true
 }
 
 func (bs *BalancerScene) stopWorker() {
@@ -378,6 +714,7 @@ func (bs *BalancerScene) stopWorker() {
 	for idx, w := range bs.Workers {
 		if !w.Killing {
 			bs.Workers[idx].Killing = true
+			bs.stateDirty = true
 			proc := w.Process
 			if proc == nil {
 				log.Printf("Proc is nil for worker: %v", w)
@@ -396,25 +733,31 @@ func (bs *BalancerScene) stopWorker() {
 
 }
 
-func (bs *BalancerScene) startBot() {
-	cmd := exec.Command("./bot", "-host", bs.ServerScene.Host, "-port", strconv.Itoa(bs.ServerScene.Port))
+// startBot starts a bot flying with brain, or with the BOT_BRAIN flag's brain when it's empty.
+func (bs *BalancerScene) startBot(brain string) {
+	args := append([]string{"-host", bs.ServerScene.Host, "-port", strconv.Itoa(bs.ServerScene.Port)}, bs.topologyArgs()...)
+	if brain != "" {
+		args = append(args, "-brain", brain)
+	}
+	cmd := exec.Command("./bot", args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	err := cmd.Start()
 	if err != nil {
 		log.Printf("Error starting ot: %+v", err)
+		return
 	}
 
-	bs.BotProcesses = append(bs.BotProcesses, cmd.Process)
+	bs.BotProcesses[brain] = append(bs.BotProcesses[brain], cmd.Process)
 }
 
-func (bs *BalancerScene) stopBot() {
-	if len(bs.BotProcesses) == 0 {
-		log.Printf("No bots to stop")
+func (bs *BalancerScene) stopBot(brain string) {
+	if len(bs.BotProcesses[brain]) == 0 {
+		log.Printf("No %s bots to stop", brain)
 		return
 	}
 
-	proc := bs.BotProcesses[0]
+	proc := bs.BotProcesses[brain][0]
 	log.Printf("Killing bot: %+v", proc)
 	err := proc.Kill()
 	if err != nil {
@@ -423,29 +766,45 @@ func (bs *BalancerScene) stopBot() {
 	}
 	p, err := proc.Wait()
 	log.Printf("P: %+v Err: %+v", p, err)
-	bs.BotProcesses = bs.BotProcesses[1:]
+	bs.BotProcesses[brain] = bs.BotProcesses[brain][1:]
+	if len(bs.BotProcesses[brain]) == 0 {
+		delete(bs.BotProcesses, brain)
+	}
 }
 
 // Simple split into vertical slices
 func (bs *BalancerScene) rebalanceAuthority() {
-	cellCount := int(math.Sqrt(float64(len(bs.Workers))))
-	xSize := int(bs.WorldBounds.Max.X-bs.WorldBounds.Min.X) / cellCount
-	ySize := int(bs.WorldBounds.Max.Y-bs.WorldBounds.Min.Y) / cellCount
-	log.Printf("Rebalance auth: Cell Count: %d XS: %d YS: %d", cellCount, xSize, ySize)
+	log.Printf("Rebalance auth: Worker Count: %d", len(bs.Workers))
+	for i, bounds := range workerGrid(len(bs.Workers), bs.WorldBounds) {
+		w := bs.Workers[i]
+		bs.setWorkerACL(w.ID, w.WorkerID, bounds)
+		log.Printf("Bounds[%d]: %+v", i, bounds)
+
+		bs.Workers[i].AABB = bounds
+		bs.stateDirty = true
+	}
+}
+
+// workerGrid splits bounds into a square grid of cells for count workers, in worker order.  Any workers past the
+// largest square that fits don't get a cell.
+func workerGrid(count int, bounds engo.AABB) []engo.AABB {
+	cellCount := int(math.Sqrt(float64(count)))
+	if cellCount == 0 {
+		return nil
+	}
+	xSize := int(bounds.Max.X-bounds.Min.X) / cellCount
+	ySize := int(bounds.Max.Y-bounds.Min.Y) / cellCount
+
+	cells := []engo.AABB{}
 	for y := 0; y < cellCount; y++ {
 		for x := 0; x < cellCount; x++ {
-			i := y*cellCount + x
-			w := bs.Workers[i]
-			bounds := engo.AABB{
+			cells = append(cells, engo.AABB{
 				Min: engo.Point{X: float32(x * xSize), Y: float32(y * ySize)},
 				Max: engo.Point{X: float32(x*xSize + xSize), Y: float32(y*ySize + ySize)},
-			}
-			bs.setWorkerACL(w.ID, w.WorkerID, bounds)
-			log.Printf("Bounds[%d, %d]: %d %+v", x, y, i, bounds)
-
-			bs.Workers[i].AABB = bounds
+			})
 		}
 	}
+	return cells
 }
 
 func (bs *BalancerScene) setWorkerACL(ID sos.EntityID, workerID string, bounds engo.AABB) {
@@ -453,6 +812,8 @@ func (bs *BalancerScene) setWorkerACL(ID sos.EntityID, workerID string, bounds e
 	ShipCID := uint32(cidShip)
 	PlayerInputCID := uint32(cidPlayerInput)
 	EffectCID := uint32(cidEffect)
+	MatchCID := uint32(cidMatch)
+	ObstacleCID := uint32(cidObstacle)
 
 	readAttrSet := []WorkerAttributeSet{
 		{[]string{"position"}},
@@ -461,8 +822,10 @@ func (bs *BalancerScene) setWorkerACL(ID sos.EntityID, workerID string, bounds e
 	readAcl := WorkerRequirementSet{AttributeSet: readAttrSet}
 	writeAcl := map[uint32]WorkerRequirementSet{
 		// Leave interest write authority for now so things keep working.  This should move to the balancer though
-		cidInterest: WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
-		cidPosition: WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidInterest:     WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"workerId:" + bs.ServerScene.WorkerID}}}},
+		cidPosition:     WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"workerId:" + bs.ServerScene.WorkerID}}}},
+		cidServerWorker: WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"workerId:" + bs.ServerScene.WorkerID}}}},
+		cidACL:          WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
 	}
 
 	boxConstraint := QBIBoxConstraint{
@@ -470,14 +833,21 @@ func (bs *BalancerScene) setWorkerACL(ID sos.EntityID, workerID string, bounds e
 		Edge:   EdgeLength{X: float64(bounds.Max.X-bounds.Min.X) * 1.1, Y: 10000, Z: float64(bounds.Max.Y-bounds.Min.Y) * 1.1},
 	}
 
+	// Cells on the edge of a wrapping world neighbour the cells on the opposite edge, so look across the seam at them.
+	area := QBIConstraint{BoxConstraint: &boxConstraint}
+	if seam := bs.World.seamStrips(boxAABB(boxConstraint), 0); len(seam) > 0 {
+		area = QBIConstraint{OrConstraint: append([]QBIConstraint{area}, seam...)}
+	}
+
 	constraint := QBIConstraint{
 		AndConstraint: []QBIConstraint{
-			QBIConstraint{BoxConstraint: &boxConstraint},
+			area,
 			QBIConstraint{
 				OrConstraint: []QBIConstraint{
 					QBIConstraint{ComponentIDConstraint: &ShipCID},
 					QBIConstraint{ComponentIDConstraint: &EffectCID},
 					QBIConstraint{ComponentIDConstraint: &PlayerInputCID},
+					QBIConstraint{ComponentIDConstraint: &ObstacleCID},
 				},
 			},
 		},
@@ -488,7 +858,8 @@ func (bs *BalancerScene) setWorkerACL(ID sos.EntityID, workerID string, bounds e
 
 			cidPosition: ComponentInterest{
 				Queries: []QBIQuery{
-					{Constraint: constraint, ResultComponents: []uint32{cidShip, cidPosition, cidEffect, cidPlayerInput}},
+					{Constraint: constraint, ResultComponents: []uint32{cidShip, cidPosition, cidEffect, cidPlayerInput, cidScore, cidTeam, cidPlayer, cidShipAppearance, cidObstacle}},
+					{Constraint: QBIConstraint{ComponentIDConstraint: &MatchCID}, ResultComponents: []uint32{cidMatch}},
 				},
 			},
 		},
@@ -502,21 +873,55 @@ func (bs *BalancerScene) setWorkerACL(ID sos.EntityID, workerID string, bounds e
 
 }
 
-func NewServerWorker() balancedWorker {
+// boxAABB is the area a box constraint covers.
+func boxAABB(box QBIBoxConstraint) engo.AABB {
+	return engo.AABB{
+		Min: engo.Point{X: float32(box.Center.X - box.Edge.X/2), Y: float32(box.Center.Z - box.Edge.Z/2)},
+		Max: engo.Point{X: float32(box.Center.X + box.Edge.X/2), Y: float32(box.Center.Z + box.Edge.Z/2)},
+	}
+}
+
+// updateSeamInterest lets a player see across the seam while their ship is near the edge of a wrapping world.
+func (bs *BalancerScene) updateSeamInterest(e *balancedEntity) {
+	if !bs.Leader || e.Client == "" {
+		return
+	}
+	view := QBIBoxConstraint{Center: e.Pos.Coords, Edge: EdgeLength{X: shipViewWidth, Z: shipViewHeight}}
+	// Strips half a view wide cover anything the view could reach, and don't change as the ship moves about.
+	seam := bs.World.seamStrips(boxAABB(view), shipViewWidth/2)
+	if reflect.DeepEqual(seam, e.Seam) {
+		return
+	}
+	e.Seam = seam
+	bs.spatial.UpdateComponent(e.ID, cidInterest, shipInterest(seam))
+}
+
+// topologyArgs passes a topology picked on our command line on to the workers we start.
+func (bs *BalancerScene) topologyArgs() []string {
+	if bs.Topology == "" {
+		return nil
+	}
+	return []string{"-topology", bs.Topology}
+}
+
+func NewServerWorker(workerID string) balancedWorker {
 
 	readAttrSet := []WorkerAttributeSet{
 		{[]string{"balancer"}},
 	}
 	readAcl := WorkerRequirementSet{AttributeSet: readAttrSet}
 	writeAcl := map[uint32]WorkerRequirementSet{
-		cidACL:      WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
-		cidInterest: WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
-		cidPosition: WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidACL:          WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidInterest:     WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidPosition:     WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidServerWorker: WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
 	}
 	worker := balancedWorker{
-		ACL:  ImprobableACL{ComponentWriteAcl: writeAcl, ReadAcl: readAcl},
-		Meta: ImprobableMetadata{Name: "Server"},
-		Pos:  ImprobablePosition{},
+		ACL:      ImprobableACL{ComponentWriteAcl: writeAcl, ReadAcl: readAcl},
+		Meta:     ImprobableMetadata{Name: "Server"},
+		Pos:      ImprobablePosition{},
+		Server:   ServerWorkerComponent{WorkerID: workerID},
+		WorkerID: workerID,
 	}
 	return worker
 }
@@ -2,6 +2,7 @@ package superspatial
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -33,3 +34,40 @@ func TestCalcRequiredWorkers(t *testing.T) {
 	}
 
 }
+
+func TestParseBotMix(t *testing.T) {
+	var tests = []struct {
+		value string
+		mix   map[string]int
+		err   bool
+	}{
+		{"", map[string]int{}, false},
+		{"3", map[string]int{"": 3}, false},
+		{"hunter:3,wanderer:5", map[string]int{"hunter": 3, "wanderer": 5}, false},
+		{" coward : 1 , rammer:2 ", map[string]int{"coward": 1, "rammer": 2}, false},
+		{"hunter:1,hunter:2", map[string]int{"hunter": 3}, false},
+		{"bots/skirmisher.json:2,wanderer:1", map[string]int{"bots/skirmisher.json": 2, "wanderer": 1}, false},
+		{"-1", nil, true},
+		{"hunter", nil, true},
+		{"genius:3", nil, true},
+		{"bots/missing.json:1", nil, true},
+		{"hunter:lots", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q", tt.value), func(t *testing.T) {
+			mix, err := parseBotMix(tt.value)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected an error, got %v", mix)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(mix, tt.mix) {
+				t.Errorf("got %v, want %v", mix, tt.mix)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package superspatial
+
+import (
+	"sort"
+
+	"github.com/ScottBrooks/sos"
+)
+
+// Any number of balancers can run at once.  Whichever one the runtime gives write authority over the Balancer
+// component to leads, the rest follow along: they keep Entities, Workers and Clients up to date from what they see, but
+// leave spawning, scaling and ACLs to the leader until the Balancer component comes to them.
+
+// BalancerStandbyComponent is on an entity each balancer makes for itself, giving it the same view of the world as
+// the leader so it's ready to take over.
+type BalancerStandbyComponent struct {
+	WorkerID string
+}
+
+type balancerStandby struct {
+	ID       sos.EntityID
+	ACL      ImprobableACL            `sos:"50"`
+	Pos      ImprobablePosition       `sos:"54"`
+	Meta     ImprobableMetadata       `sos:"53"`
+	Interest ImprobableInterest       `sos:"58"`
+	Standby  BalancerStandbyComponent `sos:"1018"`
+}
+
+// newBalancerStandby is the standby entity for the balancer workerID, interested in everything the leader is.
+func newBalancerStandby(workerID string) balancerStandby {
+	readAcl := WorkerRequirementSet{AttributeSet: []WorkerAttributeSet{{[]string{"balancer"}}}}
+	writeAcl := map[uint32]WorkerRequirementSet{
+		cidACL:             WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidPosition:        WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidInterest:        WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidBalancerStandby: WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"workerId:" + workerID}}}},
+	}
+
+	return balancerStandby{
+		ACL:  ImprobableACL{ComponentWriteAcl: writeAcl, ReadAcl: readAcl},
+		Meta: ImprobableMetadata{Name: "Balancer Standby"},
+		Interest: ImprobableInterest{
+			Interest: map[uint32]ComponentInterest{
+				cidBalancerStandby: ComponentInterest{Queries: balancerQueries()},
+			},
+		},
+		Standby: BalancerStandbyComponent{WorkerID: workerID},
+	}
+}
+
+// balancerQueries are everything a balancer keeps track of.
+func balancerQueries() []QBIQuery {
+	workerCID := uint32(cidWorker)
+	positionCID := uint32(cidPosition)
+	balancerCID := uint32(cidBalancer)
+	return []QBIQuery{
+		{Constraint: QBIConstraint{ComponentIDConstraint: &workerCID}, ResultComponents: []uint32{cidWorker, cidPlayerClient}},
+		{Constraint: QBIConstraint{ComponentIDConstraint: &positionCID}, ResultComponents: []uint32{cidACL, cidInterest, cidPosition, cidScore, cidLeaderboard, cidPlayer, cidShipAppearance, cidTeam, cidMatch, cidChat, cidGlobalChat, cidSession, cidSendMessageQueue, cidRespawnQueue, cidWorkerBalancer, cidServerWorker, cidBalancerStandby, cidObstacle}},
+		{Constraint: QBIConstraint{ComponentIDConstraint: &balancerCID}, ResultComponents: []uint32{cidBalancer}},
+	}
+}
+
+// takeOver is us becoming the leader, either at startup or because the last one went away.  We catch up with
+// everything through a resync, which keeps the layout the last leader saved.
+func (bs *BalancerScene) takeOver() {
+	if bs.Leader {
+		return
+	}
+	log.Printf("Balancer %s is taking over", bs.ServerScene.WorkerID)
+	bs.Leader = true
+	bs.Commands.HandleSendMessage(bs.handleSendMessage)
+	bs.Commands.HandleRespawn(bs.handleRespawn)
+	bs.startResync()
+}
+
+// stepDown is us losing the Balancer component, someone else is leading now.
+func (bs *BalancerScene) stepDown() {
+	if !bs.Leader {
+		return
+	}
+	log.Printf("Balancer %s is standing by", bs.ServerScene.WorkerID)
+	bs.Leader = false
+	bs.resync = nil
+	bs.Commands.Unhandle(sendMessageCommand)
+	bs.Commands.Unhandle(respawnCommand)
+	bs.BalancerID = 0
+	bs.LeaderboardID = 0
+	bs.MatchID = 0
+	bs.ChatID = 0
+}
+
+// mirrorState follows along with the leader's workers and assignments.
+func (bs *BalancerScene) mirrorState(state BalancerComponent) {
+	bs.Workers = []balancedWorker{}
+	for _, sw := range state.Workers {
+		bs.Workers = append(bs.Workers, stateWorker(sw))
+	}
+	bs.TargetWorkerCount = int(state.TargetWorkerCount)
+	bs.WorkersAdjusting = state.WorkersAdjusting
+	for ID, e := range bs.Entities {
+		if w, ok := state.Assignments[ID]; ok {
+			e.Worker.WorkerID = bs.workerIndex(w)
+		}
+	}
+}
+
+// staleStandbys are standby entities left behind by balancers that aren't connected any more.  Both standbys and
+// balancers are balancer worker ids, by entity.
+func staleStandbys(standbys map[sos.EntityID]string, balancers map[sos.EntityID]string) []sos.EntityID {
+	connected := map[string]bool{}
+	for _, workerID := range balancers {
+		connected[workerID] = true
+	}
+	stale := []sos.EntityID{}
+	for ID, workerID := range standbys {
+		if !connected[workerID] {
+			stale = append(stale, ID)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i] < stale[j] })
+	return stale
+}
+
+// removeStandby deletes the standby entity of a balancer that's disconnected.
+func (bs *BalancerScene) removeStandby(workerID string) {
+	for ID, w := range bs.standbys {
+		if w == workerID {
+			log.Printf("Deleting standby entity %d for %s", ID, workerID)
+			bs.spatial.Delete(ID)
+		}
+	}
+}
+
+// claimACL gives the components in acl written by the balancer layer, or by the leader before us, to the balancer
+// workerID.  The ACL, Balancer and standby components stay with the layer, so whoever leads next can claim them back.
+// Claiming is false if there was nothing to claim.
+func claimACL(acl ImprobableACL, lastLeader string, workerID string) (claimed ImprobableACL, claiming bool) {
+	claimed = ImprobableACL{ComponentWriteAcl: map[uint32]WorkerRequirementSet{}, ReadAcl: acl.ReadAcl}
+	for cid, rs := range acl.ComponentWriteAcl {
+		claimed.ComponentWriteAcl[cid] = rs
+		if cid == cidACL || cid == cidBalancer || cid == cidBalancerStandby {
+			continue
+		}
+		if len(rs.AttributeSet) != 1 || len(rs.AttributeSet[0].Attribute) != 1 {
+			continue
+		}
+		a := rs.AttributeSet[0].Attribute[0]
+		if a == "balancer" || (lastLeader != "" && lastLeader != workerID && a == "workerId:"+lastLeader) {
+			claimed.ComponentWriteAcl[cid] = WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"workerId:" + workerID}}}}
+			claiming = true
+		}
+	}
+	return claimed, claiming
+}
+
+// claimEntity claims e's ACL for us, taking over from lastLeader.
+func (bs *BalancerScene) claimEntity(e *balancedEntity, lastLeader string) {
+	acl, claiming := claimACL(e.ACL, lastLeader, bs.ServerScene.WorkerID)
+	if !claiming {
+		return
+	}
+	e.ACL = acl
+	bs.spatial.UpdateComponent(e.ID, cidACL, acl)
+}
@@ -0,0 +1,78 @@
+package superspatial
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EngoEngine/engo"
+	"github.com/ScottBrooks/sos"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestStaleStandbys(t *testing.T) {
+	standbys := map[sos.EntityID]string{40: "Balancer_1", 41: "Balancer_2", 42: "Balancer_3"}
+	balancers := map[sos.EntityID]string{1: "Balancer_1"}
+	if got := staleStandbys(standbys, balancers); !reflect.DeepEqual(got, []sos.EntityID{41, 42}) {
+		t.Errorf("got %v, want [41 42]", got)
+	}
+}
+
+func TestMirrorState(t *testing.T) {
+	bs := BalancerScene{
+		Workers: []balancedWorker{{WorkerID: "Server_old"}},
+		Entities: map[sos.EntityID]*balancedEntity{
+			30: {ID: 30, Worker: WorkerComponent{-1}},
+			31: {ID: 31, Worker: WorkerComponent{-1}},
+		},
+	}
+	bs.mirrorState(BalancerComponent{
+		Workers:           []BalancerWorker{{WorkerID: "Server_1", ID: 20, Bounds: mgl32.Vec4{0, 0, 100, 50}}},
+		TargetWorkerCount: 4,
+		WorkersAdjusting:  true,
+		Assignments:       map[sos.EntityID]sos.EntityID{30: 20},
+	})
+
+	if len(bs.Workers) != 1 || bs.Workers[0].WorkerID != "Server_1" || bs.Workers[0].AABB != (engo.AABB{Max: engo.Point{X: 100, Y: 50}}) {
+		t.Errorf("workers: got %+v", bs.Workers)
+	}
+	if bs.TargetWorkerCount != 4 || !bs.WorkersAdjusting {
+		t.Errorf("scaling: got %d %v", bs.TargetWorkerCount, bs.WorkersAdjusting)
+	}
+	if bs.Entities[30].Worker.WorkerID != 0 || bs.Entities[31].Worker.WorkerID != -1 {
+		t.Errorf("assignments: got %d %d", bs.Entities[30].Worker.WorkerID, bs.Entities[31].Worker.WorkerID)
+	}
+}
+
+func TestClaimACL(t *testing.T) {
+	attr := func(a string) WorkerRequirementSet {
+		return WorkerRequirementSet{[]WorkerAttributeSet{{[]string{a}}}}
+	}
+	acl := ImprobableACL{ComponentWriteAcl: map[uint32]WorkerRequirementSet{
+		cidACL:         attr("balancer"),
+		cidBalancer:    attr("balancer"),
+		cidLeaderboard: attr("balancer"),
+		cidMatch:       attr("workerId:Balancer_1"),
+		cidShip:        attr("workerId:Server_1"),
+	}}
+
+	claimed, claiming := claimACL(acl, "Balancer_1", "Balancer_2")
+	if !claiming {
+		t.Fatal("nothing claimed")
+	}
+	want := map[uint32]WorkerRequirementSet{
+		cidACL:         attr("balancer"),
+		cidBalancer:    attr("balancer"),
+		cidLeaderboard: attr("workerId:Balancer_2"),
+		cidMatch:       attr("workerId:Balancer_2"),
+		cidShip:        attr("workerId:Server_1"),
+	}
+	if !reflect.DeepEqual(claimed.ComponentWriteAcl, want) {
+		t.Errorf("got %+v", claimed.ComponentWriteAcl)
+	}
+	if acl.ComponentWriteAcl[cidMatch].AttributeSet[0].Attribute[0] != "workerId:Balancer_1" {
+		t.Error("changed the ACL we were given")
+	}
+	if _, claiming := claimACL(claimed, "Balancer_2", "Balancer_2"); claiming {
+		t.Error("claimed an ACL that was already ours")
+	}
+}
@@ -0,0 +1,111 @@
+package superspatial
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/ScottBrooks/sos"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// BalancerWorker is a server worker as the balancer sees it.  Bounds is its cell: min x, min y, max x, max y.
+type BalancerWorker struct {
+	WorkerID       string
+	ID             sos.EntityID
+	WorkerEntityID sos.EntityID
+	Bounds         mgl32.Vec4
+	Killing        bool
+}
+
+// BalancerComponent is the balancer's state, kept on its entity so a restarted balancer can carry on where it left
+// off without repartitioning the world.
+type BalancerComponent struct {
+	Workers           []BalancerWorker
+	TargetWorkerCount int32
+	WorkersAdjusting  bool
+	// Assignments are the worker each entity is assigned to, by the worker's entity.
+	Assignments map[sos.EntityID]sos.EntityID
+	// Leader is the balancer writing everything, the next one to lead takes it all over from them.
+	Leader string
+}
+
+// BalancerStateSystem writes out the balancer's state when it's dirty.
+type BalancerStateSystem struct {
+	BS *BalancerScene
+}
+
+func (*BalancerStateSystem) Remove(ecs.BasicEntity) {}
+func (bss *BalancerStateSystem) Update(dt float32) {
+	bss.BS.saveState()
+}
+
+// balancerState is the state we're running with right now.
+func (bs *BalancerScene) balancerState() BalancerComponent {
+	state := BalancerComponent{
+		Workers:           []BalancerWorker{},
+		TargetWorkerCount: int32(bs.TargetWorkerCount),
+		WorkersAdjusting:  bs.WorkersAdjusting,
+		Assignments:       map[sos.EntityID]sos.EntityID{},
+		Leader:            bs.ServerScene.WorkerID,
+	}
+	for _, w := range bs.Workers {
+		state.Workers = append(state.Workers, BalancerWorker{
+			WorkerID:       w.WorkerID,
+			ID:             w.ID,
+			WorkerEntityID: w.WorkerEntityID,
+			Bounds:         mgl32.Vec4{w.AABB.Min.X, w.AABB.Min.Y, w.AABB.Max.X, w.AABB.Max.Y},
+			Killing:        w.Killing,
+		})
+	}
+	for ID, e := range bs.Entities {
+		if e.Worker.WorkerID >= 0 && int(e.Worker.WorkerID) < len(bs.Workers) {
+			state.Assignments[ID] = bs.Workers[e.Worker.WorkerID].ID
+		}
+	}
+	return state
+}
+
+// saveState pushes our state out to the balancer entity, if it changed and we own it.
+func (bs *BalancerScene) saveState() {
+	if bs.BalancerID == 0 || bs.resync != nil || !bs.stateDirty {
+		return
+	}
+	bs.State = bs.balancerState()
+	bs.stateDirty = false
+	bs.spatial.UpdateComponent(bs.BalancerID, cidBalancer, bs.State)
+}
+
+// workerIndex is the index in Workers of the worker with entity ID, -1 if there isn't one.
+func (bs *BalancerScene) workerIndex(ID sos.EntityID) int32 {
+	for i, w := range bs.Workers {
+		if w.ID == ID {
+			return int32(i)
+		}
+	}
+	return -1
+}
+
+// stateWorker is the worker sw describes.
+func stateWorker(sw BalancerWorker) balancedWorker {
+	w := NewServerWorker(sw.WorkerID)
+	w.ID = sw.ID
+	w.WorkerEntityID = sw.WorkerEntityID
+	w.AABB = engo.AABB{Min: engo.Point{X: sw.Bounds[0], Y: sw.Bounds[1]}, Max: engo.Point{X: sw.Bounds[2], Y: sw.Bounds[3]}}
+	w.Killing = sw.Killing
+	return w
+}
+
+// restoreWorkers rebuilds the workers a previous balancer had, keeping their cells, as long as servers are exactly
+// their entities.  ok is false if any of them have gone, leaving a hole in the layout, or there are servers it didn't
+// know about, which will need cells of their own.
+func restoreWorkers(state BalancerComponent, servers map[sos.EntityID]string) (workers []balancedWorker, ok bool) {
+	if len(state.Workers) == 0 || len(state.Workers) != len(servers) {
+		return nil, false
+	}
+	for _, sw := range state.Workers {
+		if _, found := servers[sw.ID]; !found {
+			return nil, false
+		}
+		workers = append(workers, stateWorker(sw))
+	}
+	return workers, true
+}
@@ -0,0 +1,57 @@
+package superspatial
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/engo"
+	"github.com/ScottBrooks/sos"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestBalancerStateRoundTrip(t *testing.T) {
+	bs := BalancerScene{
+		TargetWorkerCount: 2,
+		Workers: []balancedWorker{
+			{ID: 20, WorkerID: "Server_1", WorkerEntityID: 5, AABB: engo.AABB{Max: engo.Point{X: 100, Y: 200}}},
+			{ID: 21, WorkerID: "Server_2", WorkerEntityID: 6, AABB: engo.AABB{Min: engo.Point{X: 100}, Max: engo.Point{X: 200, Y: 200}}, Killing: true},
+		},
+		Entities: map[sos.EntityID]*balancedEntity{
+			30: {ID: 30, Worker: WorkerComponent{1}},
+			31: {ID: 31, Worker: WorkerComponent{-1}},
+		},
+	}
+	state := bs.balancerState()
+	if state.TargetWorkerCount != 2 || len(state.Workers) != 2 {
+		t.Fatalf("got %+v", state)
+	}
+	if state.Workers[1].Bounds != (mgl32.Vec4{100, 0, 200, 200}) || !state.Workers[1].Killing {
+		t.Errorf("worker: got %+v", state.Workers[1])
+	}
+	if len(state.Assignments) != 1 || state.Assignments[30] != 21 {
+		t.Errorf("assignments: got %v", state.Assignments)
+	}
+
+	servers := map[sos.EntityID]string{20: "Server_1", 21: "Server_2"}
+	workers, ok := restoreWorkers(state, servers)
+	if !ok || len(workers) != 2 {
+		t.Fatalf("got %d workers, ok %v", len(workers), ok)
+	}
+	for i, w := range workers {
+		want := bs.Workers[i]
+		if w.ID != want.ID || w.WorkerID != want.WorkerID || w.AABB != want.AABB || w.Killing != want.Killing {
+			t.Errorf("worker %d: got %+v, want %+v", i, w, want)
+		}
+	}
+
+	// A server the state doesn't know about needs the world split again.
+	if _, ok := restoreWorkers(state, map[sos.EntityID]string{20: "Server_1", 21: "Server_2", 22: "Server_3"}); ok {
+		t.Error("kept the layout with a new server")
+	}
+	// So does one that's gone, the rest can't keep their cells with a hole in the middle.
+	if _, ok := restoreWorkers(state, map[sos.EntityID]string{21: "Server_2"}); ok {
+		t.Error("kept the layout with a server missing")
+	}
+	if _, ok := restoreWorkers(BalancerComponent{}, map[sos.EntityID]string{}); ok {
+		t.Error("kept an empty layout")
+	}
+}
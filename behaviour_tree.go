@@ -0,0 +1,395 @@
+package superspatial
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// BTStatus is the result of ticking a behaviour tree node.
+type BTStatus int
+
+const (
+	BTSuccess BTStatus = iota
+	BTFailure
+	BTRunning
+)
+
+func (s BTStatus) String() string {
+	switch s {
+	case BTSuccess:
+		return "Success"
+	case BTFailure:
+		return "Failure"
+	case BTRunning:
+		return "Running"
+	}
+	return "Unknown"
+}
+
+// Blackboard is memory shared between the nodes of a tree, and kept between ticks.
+type Blackboard map[string]interface{}
+
+func (b Blackboard) Vec3(key string) (mgl32.Vec3, bool) {
+	v, ok := b[key].(mgl32.Vec3)
+	return v, ok
+}
+
+// BTContext is what a tree gets to look at and change when it ticks.
+type BTContext struct {
+	View  WorldView
+	Input *PlayerInputComponent
+	Board Blackboard
+}
+
+type BTNode interface {
+	Tick(ctx *BTContext) BTStatus
+}
+
+// Sequence ticks its children in order until one doesn't succeed.
+type Sequence struct {
+	Children []BTNode
+}
+
+func (s *Sequence) Tick(ctx *BTContext) BTStatus {
+	for _, c := range s.Children {
+		if status := c.Tick(ctx); status != BTSuccess {
+			return status
+		}
+	}
+	return BTSuccess
+}
+
+// Selector ticks its children in order until one doesn't fail.
+type Selector struct {
+	Children []BTNode
+}
+
+func (s *Selector) Tick(ctx *BTContext) BTStatus {
+	for _, c := range s.Children {
+		if status := c.Tick(ctx); status != BTFailure {
+			return status
+		}
+	}
+	return BTFailure
+}
+
+// Decorator changes the result of its child.
+type Decorator struct {
+	Child BTNode
+	Fn    func(BTStatus) BTStatus
+}
+
+func (d *Decorator) Tick(ctx *BTContext) BTStatus {
+	return d.Fn(d.Child.Tick(ctx))
+}
+
+// Condition succeeds when Fn is true, and fails otherwise.
+type Condition struct {
+	Name string
+	Fn   func(ctx *BTContext) bool
+}
+
+func (c *Condition) Tick(ctx *BTContext) BTStatus {
+	if c.Fn(ctx) {
+		return BTSuccess
+	}
+	return BTFailure
+}
+
+// Action does something to the world, usually by pressing buttons on ctx.Input.
+type Action struct {
+	Name string
+	Fn   func(ctx *BTContext) BTStatus
+}
+
+func (a *Action) Tick(ctx *BTContext) BTStatus {
+	return a.Fn(ctx)
+}
+
+// BTParams are the tunables for a condition or action, as read from json.
+type BTParams map[string]float64
+
+func (p BTParams) Get(key string, def float64) float64 {
+	if v, ok := p[key]; ok {
+		return v
+	}
+	return def
+}
+
+var btDecorators = map[string]func(BTStatus) BTStatus{
+	"invert": func(s BTStatus) BTStatus {
+		switch s {
+		case BTSuccess:
+			return BTFailure
+		case BTFailure:
+			return BTSuccess
+		}
+		return s
+	},
+	"succeed": func(BTStatus) BTStatus { return BTSuccess },
+	"fail":    func(BTStatus) BTStatus { return BTFailure },
+}
+
+var btConditions = map[string]func(p BTParams) func(ctx *BTContext) bool{
+	// HasTarget is true when there's a ship within range.
+	"HasTarget": func(p BTParams) func(ctx *BTContext) bool {
+		r := float32(p.Get("range", botSightRange))
+		return func(ctx *BTContext) bool {
+			n := ctx.View.Nearest()
+			return n != nil && ctx.View.distanceTo(n) < r
+		}
+	},
+	// Outgunned is true when the nearest ship would beat us in a collision.
+	"Outgunned": func(p BTParams) func(ctx *BTContext) bool {
+		return func(ctx *BTContext) bool {
+			n := ctx.View.Nearest()
+			return n != nil && attackScore(n.Ship) > attackScore(ctx.View.Self.Ship)
+		}
+	},
+	// CanRam is true when we'd beat the nearest ship and it is in front of us.
+	"CanRam": func(p BTParams) func(ctx *BTContext) bool {
+		cone := float32(p.Get("cone", botRamCone))
+		return func(ctx *BTContext) bool {
+			n := ctx.View.Nearest()
+			return n != nil && canRam(ctx.View.Self.Ship, n.Ship, cone)
+		}
+	},
+	"NearWall": func(p BTParams) func(ctx *BTContext) bool {
+		margin := float32(p.Get("margin", wallMargin))
+		return func(ctx *BTContext) bool {
+			return ctx.View.avoidWalls(margin).Len() > 0
+		}
+	},
+	"NearObstacle": func(p BTParams) func(ctx *BTContext) bool {
+		margin := float32(p.Get("margin", obstacleMargin))
+		return func(ctx *BTContext) bool {
+			return avoidObstacles(ctx.View.Self.Ship, ctx.View.Obstacles, margin).Len() > 0
+		}
+	},
+	"SpeedBelow": func(p BTParams) func(ctx *BTContext) bool {
+		speed := float32(p.Get("speed", 20))
+		return func(ctx *BTContext) bool {
+			return ctx.View.Self.Ship.Vel.Len() < speed
+		}
+	},
+}
+
+var btActions = map[string]func(p BTParams) func(ctx *BTContext) BTStatus{
+	// TargetNearest puts where the nearest ship is going to be on the blackboard as "target".  With nobody around it
+	// clears "target", so nothing keeps chasing a ship we've lost.
+	"TargetNearest": func(p BTParams) func(ctx *BTContext) BTStatus {
+		return func(ctx *BTContext) BTStatus {
+			n := ctx.View.Nearest()
+			if n == nil {
+				delete(ctx.Board, "target")
+				return BTFailure
+			}
+			self := ctx.View.Self.Ship
+			ctx.Board["target"] = interceptPoint(self.Pos, self.Vel.Len(), n.Ship)
+			return BTSuccess
+		}
+	},
+	// Wander heads for a random point, kept on the blackboard as "wander", picking a new one once we get there or
+	// every interval seconds.  The point is copied to "target" for TurnToward.
+	"Wander": func(p BTParams) func(ctx *BTContext) BTStatus {
+		interval := time.Duration(p.Get("interval", 5) * float64(time.Second))
+		return func(ctx *BTContext) BTStatus {
+			self := ctx.View.Self.Ship
+			now := time.Now()
+			point, ok := ctx.Board.Vec3("wander")
+			until, _ := ctx.Board["wanderUntil"].(time.Time)
+			if !ok || point.Sub(self.Pos).Len() < self.Radius || now.After(until) {
+				b := ctx.View.Bounds
+				point = mgl32.Vec3{
+					b.Min.X + rand.Float32()*(b.Max.X-b.Min.X),
+					b.Min.Y + rand.Float32()*(b.Max.Y-b.Min.Y),
+					0,
+				}
+				ctx.Board["wander"] = point
+				ctx.Board["wanderUntil"] = now.Add(interval)
+			}
+			ctx.Board["target"] = point
+			return BTSuccess
+		}
+	},
+	// TurnToward turns toward "target", succeeding once we're facing it.
+	"TurnToward": func(p BTParams) func(ctx *BTContext) BTStatus {
+		tolerance := float32(p.Get("tolerance", 5))
+		return func(ctx *BTContext) BTStatus {
+			target, ok := ctx.Board.Vec3("target")
+			if !ok {
+				return BTFailure
+			}
+			self := ctx.View.Self.Ship
+			turn := angleDelta(self.Angle, headingAngle(target.Sub(self.Pos)))
+			if math.Abs(float64(turn)) <= float64(tolerance) {
+				return BTSuccess
+			}
+			ctx.Input.Left = turn < 0
+			ctx.Input.Right = turn > 0
+			return BTRunning
+		}
+	},
+	"Thrust": func(p BTParams) func(ctx *BTContext) BTStatus {
+		return func(ctx *BTContext) BTStatus {
+			ctx.Input.Forward = true
+			return BTSuccess
+		}
+	},
+	"Attack": func(p BTParams) func(ctx *BTContext) BTStatus {
+		return func(ctx *BTContext) BTStatus {
+			ctx.Input.Attack = true
+			return BTSuccess
+		}
+	},
+	// Brake thrusts against our velocity until we're slower than speed.
+	"Brake": func(p BTParams) func(ctx *BTContext) BTStatus {
+		speed := float32(p.Get("speed", 20))
+		return func(ctx *BTContext) BTStatus {
+			self := ctx.View.Self.Ship
+			if self.Vel.Len() < speed {
+				return BTSuccess
+			}
+			if angleDist(self.Angle, headingAngle(self.Vel)) < 90 {
+				ctx.Input.Back = true
+			} else {
+				ctx.Input.Forward = true
+			}
+			return BTRunning
+		}
+	},
+	// FleeFromNearest steers away from the nearest ship, failing if there's nothing to run from.
+	"FleeFromNearest": func(p BTParams) func(ctx *BTContext) BTStatus {
+		return func(ctx *BTContext) BTStatus {
+			n := ctx.View.Nearest()
+			if n == nil {
+				return BTFailure
+			}
+			*ctx.Input = steerInput(ctx.View.Self.Ship, evade(ctx.View.Self.Ship, n.Ship))
+			return BTRunning
+		}
+	},
+	// AvoidWalls steers back toward the middle of the world, failing if no walls are near.
+	"AvoidWalls": func(p BTParams) func(ctx *BTContext) BTStatus {
+		margin := float32(p.Get("margin", wallMargin))
+		return func(ctx *BTContext) BTStatus {
+			steer := ctx.View.avoidWalls(margin)
+			if steer.Len() == 0 {
+				return BTFailure
+			}
+			*ctx.Input = steerInput(ctx.View.Self.Ship, steer)
+			return BTRunning
+		}
+	},
+	// AvoidObstacles steers around the obstacle we're heading for, failing if we're not heading for one.
+	"AvoidObstacles": func(p BTParams) func(ctx *BTContext) BTStatus {
+		margin := float32(p.Get("margin", obstacleMargin))
+		return func(ctx *BTContext) BTStatus {
+			steer := avoidObstacles(ctx.View.Self.Ship, ctx.View.Obstacles, margin)
+			if steer.Len() == 0 {
+				return BTFailure
+			}
+			*ctx.Input = steerInput(ctx.View.Self.Ship, steer)
+			return BTRunning
+		}
+	},
+}
+
+// BTNodeConfig is the json form of a behaviour tree.  Type is one of sequence, selector, decorator, condition or
+// action.  Name picks the decorator, condition or action.
+//
+//	{"type": "selector", "children": [
+//		{"type": "sequence", "children": [
+//			{"type": "condition", "name": "Outgunned"},
+//			{"type": "action", "name": "FleeFromNearest"}
+//		]},
+//		{"type": "action", "name": "Brake", "params": {"speed": 50}}
+//	]}
+type BTNodeConfig struct {
+	Type     string         `json:"type"`
+	Name     string         `json:"name"`
+	Params   BTParams       `json:"params"`
+	Children []BTNodeConfig `json:"children"`
+}
+
+// Build turns the config into a tree that can be ticked.
+func (c BTNodeConfig) Build() (BTNode, error) {
+	children := []BTNode{}
+	for _, cc := range c.Children {
+		child, err := cc.Build()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	switch c.Type {
+	case "sequence":
+		return &Sequence{Children: children}, nil
+	case "selector":
+		return &Selector{Children: children}, nil
+	case "decorator":
+		fn, ok := btDecorators[c.Name]
+		if !ok {
+			return nil, fmt.Errorf("Unknown decorator: %s", c.Name)
+		}
+		if len(children) != 1 {
+			return nil, fmt.Errorf("Decorator %s needs exactly one child, got %d", c.Name, len(children))
+		}
+		return &Decorator{Child: children[0], Fn: fn}, nil
+	case "condition":
+		fn, ok := btConditions[c.Name]
+		if !ok {
+			return nil, fmt.Errorf("Unknown condition: %s", c.Name)
+		}
+		return &Condition{Name: c.Name, Fn: fn(c.Params)}, nil
+	case "action":
+		fn, ok := btActions[c.Name]
+		if !ok {
+			return nil, fmt.Errorf("Unknown action: %s", c.Name)
+		}
+		return &Action{Name: c.Name, Fn: fn(c.Params)}, nil
+	}
+	return nil, fmt.Errorf("Unknown node type: %s", c.Type)
+}
+
+// LoadBehaviourTree reads a json behaviour tree.
+func LoadBehaviourTree(r io.Reader) (BTNode, error) {
+	var config BTNodeConfig
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return nil, err
+	}
+	return config.Build()
+}
+
+func LoadBehaviourTreeFile(path string) (BTNode, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadBehaviourTree(f)
+}
+
+// BehaviourTreeBrain flies a bot by ticking a tree from the root every frame.
+type BehaviourTreeBrain struct {
+	Root  BTNode
+	Board Blackboard
+}
+
+func (bt *BehaviourTreeBrain) Think(view WorldView) PlayerInputComponent {
+	if bt.Board == nil {
+		bt.Board = Blackboard{}
+	}
+	var p PlayerInputComponent
+	bt.Root.Tick(&BTContext{View: view, Input: &p, Board: bt.Board})
+	return p
+}
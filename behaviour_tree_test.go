@@ -0,0 +1,192 @@
+package superspatial
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EngoEngine/engo"
+	"github.com/ScottBrooks/sos"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// fakeWorld builds a view with us at the centre of a 1000x1000 world, and the given ships around us.
+func fakeWorld(self ShipComponent, others ...ShipComponent) WorldView {
+	view := WorldView{
+		Self:   &TrackedEntity{ID: 1, Ship: self},
+		Bounds: engo.AABB{Max: engo.Point{X: 1000, Y: 1000}},
+	}
+	for i, o := range others {
+		view.Nearby = append(view.Nearby, &TrackedEntity{ID: sos.EntityID(2 + i), Ship: o})
+	}
+	return view
+}
+
+func tickStatus(status BTStatus) BTNode {
+	return &Action{Name: status.String(), Fn: func(*BTContext) BTStatus { return status }}
+}
+
+func TestCompositeNodes(t *testing.T) {
+	var tests = []struct {
+		name   string
+		node   BTNode
+		status BTStatus
+	}{
+		{"empty sequence", &Sequence{}, BTSuccess},
+		{"sequence all succeed", &Sequence{Children: []BTNode{tickStatus(BTSuccess), tickStatus(BTSuccess)}}, BTSuccess},
+		{"sequence stops at failure", &Sequence{Children: []BTNode{tickStatus(BTSuccess), tickStatus(BTFailure), tickStatus(BTRunning)}}, BTFailure},
+		{"sequence stops at running", &Sequence{Children: []BTNode{tickStatus(BTRunning), tickStatus(BTFailure)}}, BTRunning},
+		{"empty selector", &Selector{}, BTFailure},
+		{"selector stops at success", &Selector{Children: []BTNode{tickStatus(BTFailure), tickStatus(BTSuccess), tickStatus(BTRunning)}}, BTSuccess},
+		{"selector stops at running", &Selector{Children: []BTNode{tickStatus(BTRunning), tickStatus(BTSuccess)}}, BTRunning},
+		{"invert success", &Decorator{Child: tickStatus(BTSuccess), Fn: btDecorators["invert"]}, BTFailure},
+		{"invert running", &Decorator{Child: tickStatus(BTRunning), Fn: btDecorators["invert"]}, BTRunning},
+		{"succeed failure", &Decorator{Child: tickStatus(BTFailure), Fn: btDecorators["succeed"]}, BTSuccess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &BTContext{Input: &PlayerInputComponent{}, Board: Blackboard{}}
+			status := tt.node.Tick(ctx)
+			if status != tt.status {
+				t.Errorf("got %v, want %v", status, tt.status)
+			}
+		})
+	}
+}
+
+func TestTurnTowardAndThrust(t *testing.T) {
+	tree, err := LoadBehaviourTree(strings.NewReader(`{
+		"type": "sequence",
+		"children": [
+			{"type": "action", "name": "TargetNearest"},
+			{"type": "action", "name": "TurnToward"},
+			{"type": "action", "name": "Thrust"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	brain := &BehaviourTreeBrain{Root: tree}
+
+	self := ShipComponent{Pos: mgl32.Vec3{500, 500, 0}, Radius: 32}
+
+	// Target is directly below us(positive angle), so we turn right and hold off thrusting.
+	p := brain.Think(fakeWorld(self, ShipComponent{Pos: mgl32.Vec3{500, 700, 0}, Radius: 32}))
+	if !p.Right || p.Left || p.Forward {
+		t.Errorf("expected to turn right without thrust, got %+v", p)
+	}
+	target, ok := brain.Board.Vec3("target")
+	if !ok || target != (mgl32.Vec3{500, 700, 0}) {
+		t.Errorf("expected target on the blackboard, got %v", target)
+	}
+
+	// Target is dead ahead, so we thrust.
+	p = brain.Think(fakeWorld(self, ShipComponent{Pos: mgl32.Vec3{700, 500, 0}, Radius: 32}))
+	if p.Left || p.Right || !p.Forward {
+		t.Errorf("expected to thrust straight ahead, got %+v", p)
+	}
+
+	// Nobody around, so the sequence fails before pressing anything.
+	p = brain.Think(fakeWorld(self))
+	if p != (PlayerInputComponent{}) {
+		t.Errorf("expected no input, got %+v", p)
+	}
+}
+
+func TestFleeWhenOutgunned(t *testing.T) {
+	tree, err := LoadBehaviourTree(strings.NewReader(`{
+		"type": "selector",
+		"children": [
+			{
+				"type": "sequence",
+				"children": [
+					{"type": "condition", "name": "Outgunned"},
+					{"type": "action", "name": "FleeFromNearest"}
+				]
+			},
+			{"type": "action", "name": "Brake", "params": {"speed": 50}}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	brain := &BehaviourTreeBrain{Root: tree}
+
+	// We're pointing right, at a ship coming at us faster than we're going.
+	self := ShipComponent{Pos: mgl32.Vec3{500, 500, 0}, Vel: mgl32.Vec3{100, 0, 0}, Radius: 32}
+	threat := ShipComponent{Pos: mgl32.Vec3{700, 500, 0}, Vel: mgl32.Vec3{-400, 0, 0}, Angle: 180, Radius: 32}
+	p := brain.Think(fakeWorld(self, threat))
+	if p.Forward || !(p.Left || p.Right) {
+		t.Errorf("expected to turn away from the threat, got %+v", p)
+	}
+
+	// A slow ship isn't a threat, so we brake by reversing against our velocity.
+	threat.Vel = mgl32.Vec3{0, 0, 0}
+	p = brain.Think(fakeWorld(self, threat))
+	if !p.Back {
+		t.Errorf("expected to brake, got %+v", p)
+	}
+}
+
+func TestLoadBehaviourTreeErrors(t *testing.T) {
+	for _, js := range []string{
+		`{"type": "parallel"}`,
+		`{"type": "action", "name": "Teleport"}`,
+		`{"type": "condition", "name": "IsFriday"}`,
+		`{"type": "decorator", "name": "invert"}`,
+		`{"type": "decorator", "name": "repeat", "children": [{"type": "action", "name": "Thrust"}]}`,
+		`not json`,
+	} {
+		if _, err := LoadBehaviourTree(strings.NewReader(js)); err == nil {
+			t.Errorf("expected an error loading %s", js)
+		}
+	}
+}
+
+func TestShippedTreesLoad(t *testing.T) {
+	f, err := os.Open("bots")
+	if err != nil {
+		t.Fatalf("unable to open bots: %v", err)
+	}
+	names, err := f.Readdirnames(0)
+	f.Close()
+	if err != nil {
+		t.Fatalf("unable to list bots: %v", err)
+	}
+	for _, name := range names {
+		if _, err := NewBotBrain("bots/" + name); err != nil {
+			t.Errorf("unable to load %s: %v", name, err)
+		}
+	}
+}
+
+func TestWanderRepicks(t *testing.T) {
+	wander := btActions["Wander"](BTParams{"interval": 5})
+	targetNearest := btActions["TargetNearest"](nil)
+	self := ShipComponent{Pos: mgl32.Vec3{500, 500, 0}, Radius: 32}
+	ctx := &BTContext{View: fakeWorld(self), Input: &PlayerInputComponent{}, Board: Blackboard{}}
+
+	// Losing our target clears it, rather than leaving a stale intercept point about.
+	ctx.Board["target"] = mgl32.Vec3{1, 2, 0}
+	if targetNearest(ctx) != BTFailure {
+		t.Fatal("expected to fail with nobody around")
+	}
+	if _, ok := ctx.Board.Vec3("target"); ok {
+		t.Errorf("expected the old target to be cleared")
+	}
+
+	wander(ctx)
+	point, _ := ctx.Board.Vec3("wander")
+	if target, _ := ctx.Board.Vec3("target"); target != point {
+		t.Errorf("got target %v, want the wander point %v", target, point)
+	}
+
+	// Out of time, we pick somewhere else even though we haven't got there.
+	ctx.Board["wander"] = mgl32.Vec3{-1, -1, 0}
+	ctx.Board["wanderUntil"] = time.Now().Add(-time.Second)
+	wander(ctx)
+	if point, _ := ctx.Board.Vec3("wander"); point == (mgl32.Vec3{-1, -1, 0}) {
+		t.Errorf("expected a new wander point once the interval was up")
+	}
+}
@@ -0,0 +1,192 @@
+package superspatial
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EngoEngine/engo"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const defaultBotBrain = "hunter"
+
+// WorldView is everything a bot gets to know about the world when deciding what to do.
+type WorldView struct {
+	Self *TrackedEntity
+	// Nearby ships, closest first.
+	Nearby    []*TrackedEntity
+	Obstacles []ObstacleComponent
+	Bounds    engo.AABB
+	// Wraps is set when the edges of the world join up, and there are no walls to avoid.
+	Wraps bool
+}
+
+// Nearest returns the closest ship, or nil if there isn't one.
+func (wv WorldView) Nearest() *TrackedEntity {
+	if len(wv.Nearby) == 0 {
+		return nil
+	}
+	return wv.Nearby[0]
+}
+
+func (wv WorldView) distanceTo(e *TrackedEntity) float32 {
+	return e.Ship.Pos.Sub(wv.Self.Ship.Pos).Len()
+}
+
+// avoidWalls steers away from the edges of a bounded world.
+func (wv WorldView) avoidWalls(margin float32) mgl32.Vec3 {
+	if wv.Wraps {
+		return mgl32.Vec3{}
+	}
+	return avoidWalls(wv.Self.Ship, wv.Bounds, margin)
+}
+
+// BotBrain decides how a bot should fly.
+type BotBrain interface {
+	Think(view WorldView) PlayerInputComponent
+}
+
+var botBrains = map[string]func() BotBrain{
+	"wanderer": func() BotBrain { return &WandererBrain{} },
+	"hunter":   func() BotBrain { return &HunterBrain{} },
+	"coward":   func() BotBrain { return &CowardBrain{} },
+	"rammer":   func() BotBrain { return &RammerBrain{} },
+}
+
+// NewBotBrain creates a brain by name: wanderer, hunter, coward or rammer.  Names ending in .json are loaded as a
+// behaviour tree.
+func NewBotBrain(name string) (BotBrain, error) {
+	if strings.HasSuffix(name, ".json") {
+		root, err := LoadBehaviourTreeFile(name)
+		if err != nil {
+			return nil, err
+		}
+		return &BehaviourTreeBrain{Root: root}, nil
+	}
+
+	fn, ok := botBrains[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown bot brain: %s", name)
+	}
+	return fn(), nil
+}
+
+// BotBrainNames lists every brain NewBotBrain knows about.
+func BotBrainNames() []string {
+	names := []string{}
+	for name := range botBrains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// finishInput applies the steering rules every brain shares: stay off the walls and out of obstacles unless committed
+// to a ram, and keep moving.
+func finishInput(view WorldView, desired mgl32.Vec3, ramming bool) PlayerInputComponent {
+	self := view.Self.Ship
+
+	// Walls win over everything but a committed ram.
+	if walls := view.avoidWalls(wallMargin); walls.Len() > 0 && !ramming {
+		desired = safeNormalize(desired.Add(walls.Mul(2)))
+	}
+	if rocks := avoidObstacles(self, view.Obstacles, obstacleMargin); rocks.Len() > 0 && !ramming {
+		desired = safeNormalize(desired.Add(rocks.Mul(2)))
+	}
+
+	p := steerInput(self, desired)
+	if ramming {
+		p.Forward = true
+		p.Attack = true
+	}
+	// Always keep a little speed up, a stationary ship can't win a collision.
+	if self.Vel.Len() < 20 {
+		p.Forward = true
+	}
+	return p
+}
+
+// WandererBrain ignores everyone and flies between random waypoints.
+type WandererBrain struct {
+	Waypoint       mgl32.Vec3
+	NextWaypointAt time.Time
+}
+
+func (wb *WandererBrain) steer(view WorldView) mgl32.Vec3 {
+	self := view.Self.Ship
+
+	now := time.Now()
+	if wb.NextWaypointAt.Sub(now) < 0 || wb.Waypoint.Sub(self.Pos).Len() < self.Radius {
+		wb.NextWaypointAt = now.Add(time.Duration(5+rand.Intn(10)) * time.Second)
+		wb.Waypoint = mgl32.Vec3{
+			view.Bounds.Min.X + rand.Float32()*(view.Bounds.Max.X-view.Bounds.Min.X),
+			view.Bounds.Min.Y + rand.Float32()*(view.Bounds.Max.Y-view.Bounds.Min.Y),
+			0,
+		}
+	}
+	return seek(self.Pos, wb.Waypoint)
+}
+
+func (wb *WandererBrain) Think(view WorldView) PlayerInputComponent {
+	return finishInput(view, wb.steer(view), false)
+}
+
+// HunterBrain chases the nearest ship, rams when it would win and runs when it wouldn't.
+type HunterBrain struct {
+	WandererBrain
+}
+
+func (hb *HunterBrain) Think(view WorldView) PlayerInputComponent {
+	self := view.Self.Ship
+	target := view.Nearest()
+
+	switch {
+	case target == nil:
+		return finishInput(view, hb.steer(view), false)
+	case canRam(self, target.Ship, botRamCone):
+		return finishInput(view, pursue(self, target.Ship), true)
+	case attackScore(target.Ship) > attackScore(self) && view.distanceTo(target) < botEvadeRange:
+		return finishInput(view, evade(self, target.Ship), false)
+	}
+	return finishInput(view, pursue(self, target.Ship), false)
+}
+
+// CowardBrain wanders, and runs from anything that gets close.
+type CowardBrain struct {
+	WandererBrain
+}
+
+func (cb *CowardBrain) Think(view WorldView) PlayerInputComponent {
+	self := view.Self.Ship
+
+	var desired mgl32.Vec3
+	for _, e := range view.Nearby {
+		if view.distanceTo(e) > botEvadeRange*2 {
+			break
+		}
+		desired = desired.Add(evade(self, e.Ship))
+	}
+	if desired.Len() == 0 {
+		return finishInput(view, cb.steer(view), false)
+	}
+	return finishInput(view, safeNormalize(desired), false)
+}
+
+// RammerBrain goes straight for the nearest ship, and never backs off.
+type RammerBrain struct {
+	WandererBrain
+}
+
+func (rb *RammerBrain) Think(view WorldView) PlayerInputComponent {
+	self := view.Self.Ship
+	target := view.Nearest()
+	if target == nil {
+		return finishInput(view, rb.steer(view), false)
+	}
+
+	// Any chance is a good chance, so use a wider cone than the hunter.
+	return finishInput(view, pursue(self, target.Ship), canRam(self, target.Ship, botRamCone*2))
+}
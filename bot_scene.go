@@ -12,6 +12,8 @@ type TrackedEntity struct {
 	Ship        ShipComponent
 	Pos         ImprobablePosition
 	PlayerInput PlayerInputComponent
+	Team        TeamComponent
+	Obstacle    ObstacleComponent
 }
 
 type BotScene struct {
@@ -19,6 +21,9 @@ type BotScene struct {
 
 	Entities map[sos.EntityID]*TrackedEntity
 
+	// Brain is the name of the BotBrain to fly with.  When empty the BOT_BRAIN worker flag is used instead.
+	Brain string
+
 	BotAI BotAISystem
 }
 
@@ -33,8 +38,11 @@ func (bs *BotScene) Setup(u engo.Updater) {
 	bs.Clients = map[sos.EntityID][]sos.EntityID{}
 
 	bs.ServerScene.OnCreateFunc = map[sos.RequestID]func(ID sos.EntityID){}
+	bs.ServerScene.Commands = Commands{}
+	bs.setupWorld(worldBounds)
 
-	bs.BotAI = BotAISystem{SS: &bs.ServerScene}
+	bs.BotAI = BotAISystem{SS: &bs.ServerScene, Entities: bs.Entities}
+	bs.setBrain(bs.Brain)
 
 	log.Printf("New spatialsystem")
 
@@ -43,12 +51,36 @@ func (bs *BotScene) Setup(u engo.Updater) {
 }
 func (*BotScene) Type() string { return "Bot" }
 
+func (bs *BotScene) setBrain(name string) {
+	if name == "" {
+		name = defaultBotBrain
+	}
+	brain, err := NewBotBrain(name)
+	if err != nil {
+		log.Printf("Error creating brain, using %s: %v", defaultBotBrain, err)
+		brain, _ = NewBotBrain(defaultBotBrain)
+	}
+	bs.BotAI.Brain = brain
+}
+
+func (bs *BotScene) OnFlagUpdate(op sos.FlagUpdateOp) {
+	bs.ServerScene.OnFlagUpdate(op)
+	// A brain picked on the command line wins over the worker flag.
+	if op.Key == "BOT_BRAIN" && bs.Brain == "" {
+		log.Printf("Switching brain to: %s", op.Value)
+		bs.setBrain(op.Value)
+	}
+}
+
 func (bs *BotScene) OnAddEntity(op sos.AddEntityOp) {
 	bs.Entities[op.ID] = &TrackedEntity{ID: op.ID}
 }
 
 func (bs *BotScene) OnRemoveEntity(op sos.RemoveEntityOp) {
 	if bs.Entities[op.ID] != nil {
+		if bs.BotAI.Ship == bs.Entities[op.ID] {
+			bs.BotAI.Ship = nil
+		}
 		delete(bs.Entities, op.ID)
 	}
 }
@@ -56,18 +88,31 @@ func (bs *BotScene) OnCreateEntity(op sos.CreateEntityOp) {
 	bs.ServerScene.OnCreateEntity(op)
 }
 
+func (bs *BotScene) OnAddComponent(op sos.AddComponentOp) {
+	bs.ServerScene.OnAddComponent(op)
+	bs.trackComponent(op.ID, op.Component)
+}
+
 func (bs *BotScene) OnComponentUpdate(op sos.ComponentUpdateOp) {
-	ent, ok := bs.Entities[op.ID]
+	bs.trackComponent(op.ID, op.Component)
+}
+
+func (bs *BotScene) trackComponent(ID sos.EntityID, component interface{}) {
+	ent, ok := bs.Entities[ID]
 
 	if !ok {
 		return
 	}
 
-	switch c := op.Component.(type) {
+	switch c := component.(type) {
 	case *ImprobablePosition:
 		ent.Pos = *c
 	case *ShipComponent:
 		ent.Ship = *c
+	case *TeamComponent:
+		ent.Team = *c
+	case *ObstacleComponent:
+		ent.Obstacle = *c
 	}
 }
 
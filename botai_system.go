@@ -1,58 +1,72 @@
 package superspatial
 
 import (
-	"math/rand"
-	"time"
+	"sort"
 
 	"github.com/EngoEngine/ecs"
 	"github.com/EngoEngine/engo"
 	"github.com/EngoEngine/engo/common"
+	"github.com/ScottBrooks/sos"
+)
+
+const (
+	// Ships further away than this are ignored.
+	botSightRange = 800
+	// Threats closer than this make us run.
+	botEvadeRange = 300
+	// How far off our nose a target can be and still be worth ramming.
+	botRamCone = 20
 )
 
 type BotAISystem struct {
 	SS *ServerScene
 
-	Ship *TrackedEntity
-
-	NextTurnAt time.Time
-	StopTurnAt time.Time
+	Ship     *TrackedEntity
+	Entities map[sos.EntityID]*TrackedEntity
+	Brain    BotBrain
 }
 
 func (bas *BotAISystem) Add(ent *ecs.BasicEntity, sc *common.SpaceComponent, offset engo.Point) {
 }
 func (bas *BotAISystem) Remove(ecs.BasicEntity) {}
 func (bas *BotAISystem) Update(dt float32) {
-	if bas.Ship != nil {
-		speed := bas.Ship.Ship.Vel.Len()
-		if speed < 20 {
-			bas.Ship.PlayerInput.Forward = true
-		}
+	if bas.Ship != nil && bas.Brain != nil {
+		bas.Ship.PlayerInput = bas.Brain.Think(bas.view())
 
-		now := time.Now()
-		if bas.NextTurnAt.Sub(now) < 0 {
-			bas.NextTurnAt = now.Add(time.Duration(rand.Intn(10)) * time.Second)
-			bas.StopTurnAt = now.Add(2 * time.Second)
-
-			coin := rand.Intn(2)
-			if coin == 0 {
-				//log.Printf("Turning left)")
-				bas.Ship.PlayerInput.Left = true
-				bas.Ship.PlayerInput.Right = false
-			} else {
-				//log.Printf("Turning right")
-				bas.Ship.PlayerInput.Left = false
-				bas.Ship.PlayerInput.Right = true
+		bas.SS.spatial.UpdateComponent(bas.Ship.ID, cidPlayerInput, bas.Ship.PlayerInput)
+	}
+}
+
+// view collects the enemy ships we can see, closest first, and the obstacles around us.  In a wrapping world
+// everything is placed wherever it's closest to us, which may be across the seam.
+func (bas *BotAISystem) view() WorldView {
+	world := &bas.SS.World
+	self := bas.Ship.Ship.Pos
+	view := WorldView{Self: bas.Ship, Bounds: world.Bounds, Wraps: world.Wraps()}
+	for id, e := range bas.Entities {
+		if r := e.Obstacle.BoundingRadius(); r > 0 {
+			o := e.Obstacle
+			o.Pos = self.Add(world.Delta(self, o.Pos))
+			if o.Pos.Sub(self).Len()-r < botSightRange {
+				view.Obstacles = append(view.Obstacles, o)
 			}
-			bas.Ship.PlayerInput.Forward = true
+			continue
 		}
-		if bas.StopTurnAt.Sub(now) < 0 {
-			bas.Ship.PlayerInput.Left = false
-			bas.Ship.PlayerInput.Right = false
-			bas.Ship.PlayerInput.Forward = false
-
+		// Entities without a ship(effects) have a zero radius.
+		if id == bas.Ship.ID || e.Ship.Radius == 0 || sameTeam(e.Team, bas.Ship.Team) {
+			continue
+		}
+		if world.Wraps() {
+			placed := *e
+			placed.Ship.Pos = self.Add(world.Delta(self, e.Ship.Pos))
+			e = &placed
+		}
+		if view.distanceTo(e) < botSightRange {
+			view.Nearby = append(view.Nearby, e)
 		}
-
-		// Disable AI updating
-		bas.SS.spatial.UpdateComponent(bas.Ship.ID, cidPlayerInput, bas.Ship.PlayerInput)
 	}
+	sort.Slice(view.Nearby, func(i, j int) bool {
+		return view.distanceTo(view.Nearby[i]) < view.distanceTo(view.Nearby[j])
+	})
+	return view
 }
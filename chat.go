@@ -0,0 +1,363 @@
+package superspatial
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ScottBrooks/sos"
+)
+
+// Chat channels.  Local messages go to everyone close enough to see the sender's ship.
+const (
+	ChatLocal int32 = iota
+	ChatGlobal
+)
+
+const (
+	maxChatLength = 120
+	chatTimeout   = 5 * time.Second
+	// Players get a burst of chatBurst messages, then one more every chatRefill.
+	chatBurst  = 5
+	chatRefill = 2 * time.Second
+	// How many messages a chat component holds, when they can't be sent as events.
+	chatHistory = 20
+)
+
+// The event chat components send messages with, by its index in the schema.
+const chatSaid = 1
+
+// ChatComponent lives on every ship, its messages are heard by anyone in range.  They're sent as events, or when the
+// runtime can't send those, the component holds the latest few and clients show the ones they haven't seen yet.
+type ChatComponent struct {
+	Recent []ChatMessage
+}
+
+// GlobalChatComponent lives on the chat entity in the snapshot, every client hears its messages.
+type GlobalChatComponent struct {
+	Recent []ChatMessage
+}
+
+// ChatMessage is something someone said.  Seq goes up with every message the balancer sends out, so a client can tell
+// which ones it's already heard.
+type ChatMessage struct {
+	Sender  string
+	Text    string
+	Channel int32
+	Seq     int64
+}
+
+func (m ChatMessage) String() string {
+	if m.Channel == ChatGlobal {
+		return "[All] " + m.Sender + ": " + m.Text
+	}
+	return m.Sender + ": " + m.Text
+}
+
+// sendMessageCommand is sent to a player's session to say something.
+var sendMessageCommand = CommandKey{cidSession, sessionSendMessage}
+
+type SendMessageRequest struct {
+	Seq     int64
+	Text    string
+	Channel int32
+}
+
+func (r SendMessageRequest) RequestSeq() int64 { return r.Seq }
+
+type SendMessageResponse struct {
+	Seq      int64
+	Accepted bool
+	Reason   string
+}
+
+func (r SendMessageResponse) ResponseSeq() int64 { return r.Seq }
+
+// SendMessageQueue and SendMessageAcks carry sendMessageCommand when the runtime can't.
+type SendMessageQueue struct {
+	Requests []SendMessageRequest
+}
+
+func (q SendMessageQueue) QueuedRequests() []CommandRequest {
+	reqs := make([]CommandRequest, len(q.Requests))
+	for i, r := range q.Requests {
+		reqs[i] = r
+	}
+	return reqs
+}
+
+func newSendMessageQueue(reqs []CommandRequest) interface{} {
+	q := SendMessageQueue{Requests: []SendMessageRequest{}}
+	for _, r := range reqs {
+		q.Requests = append(q.Requests, r.(SendMessageRequest))
+	}
+	return q
+}
+
+type SendMessageAcks struct {
+	Responses []SendMessageResponse
+}
+
+func (a SendMessageAcks) AckedResponses() []CommandResponse {
+	resps := make([]CommandResponse, len(a.Responses))
+	for i, r := range a.Responses {
+		resps[i] = r
+	}
+	return resps
+}
+
+func newSendMessageAcks(resps []CommandResponse) interface{} {
+	a := SendMessageAcks{Responses: []SendMessageResponse{}}
+	for _, r := range resps {
+		a.Responses = append(a.Responses, r.(SendMessageResponse))
+	}
+	return a
+}
+
+// HandleSendMessage answers sendMessageCommand with h.
+func (c *Commands) HandleSendMessage(h func(ID sos.EntityID, req SendMessageRequest) SendMessageResponse) {
+	c.Handle(sendMessageCommand, func(ID sos.EntityID, req CommandRequest) CommandResponse {
+		r := req.(SendMessageRequest)
+		resp := h(ID, r)
+		resp.Seq = r.Seq
+		return resp
+	})
+}
+
+// SendMessage sends req to the session entity ID, done is called with the balancer's response once it's back.
+func (ss *ServerScene) SendMessage(ID sos.EntityID, req SendMessageRequest, done func(SendMessageResponse, error)) {
+	req.Seq = ss.Commands.nextSeq()
+	ss.sendCommand(ID, sendMessageCommand, req, func(r CommandResult) {
+		if r.Err != nil {
+			done(SendMessageResponse{}, r.Err)
+			return
+		}
+		done(r.Response.(SendMessageResponse), nil)
+	})
+}
+
+// ChatFilter can rewrite or reject a message before it's sent out, the error is shown to the sender.  It's the hook
+// for profanity filters.
+type ChatFilter func(sender string, text string) (string, error)
+
+var ErrEmptyChat = errors.New("Nothing to say")
+
+// WordFilter masks out any of words, ignoring case.
+func WordFilter(words ...string) ChatFilter {
+	return func(sender string, text string) (string, error) {
+		masked := []rune(text)
+		// Lower casing rune by rune keeps the indices lined up with masked.
+		lower := []rune(strings.Map(unicode.ToLower, text))
+		for _, w := range words {
+			word := []rune(strings.ToLower(strings.TrimSpace(w)))
+			if len(word) == 0 {
+				continue
+			}
+			for i := 0; i+len(word) <= len(lower); i++ {
+				if string(lower[i:i+len(word)]) != string(word) {
+					continue
+				}
+				for j := i; j < i+len(word); j++ {
+					masked[j] = '*'
+				}
+			}
+		}
+		return string(masked), nil
+	}
+}
+
+// CleanChatText drops anything unprintable and trims a message down to size.
+func CleanChatText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsPrint(r) {
+			return r
+		}
+		return -1
+	}, text)
+	text = strings.TrimSpace(text)
+	if r := []rune(text); len(r) > maxChatLength {
+		text = string(r[:maxChatLength])
+	}
+	return text
+}
+
+// filterChat cleans text and runs it through filters.
+func filterChat(filters []ChatFilter, sender string, text string) (string, error) {
+	text = CleanChatText(text)
+	for _, f := range filters {
+		var err error
+		text, err = f(sender, text)
+		if err != nil {
+			return "", err
+		}
+	}
+	if text == "" {
+		return "", ErrEmptyChat
+	}
+	return text, nil
+}
+
+// chatLimiter stops players flooding the chat.
+type chatLimiter struct {
+	buckets map[string]chatBucket
+}
+
+type chatBucket struct {
+	Tokens float64
+	At     time.Time
+}
+
+// Allow reports if sender can send a message now, using one up if they can.
+func (cl *chatLimiter) Allow(sender string, now time.Time) bool {
+	if cl.buckets == nil {
+		cl.buckets = map[string]chatBucket{}
+	}
+	b, ok := cl.buckets[sender]
+	if !ok {
+		b = chatBucket{Tokens: chatBurst, At: now}
+	}
+	b.Tokens += float64(now.Sub(b.At)) / float64(chatRefill)
+	if b.Tokens > chatBurst {
+		b.Tokens = chatBurst
+	}
+	b.At = now
+
+	allowed := b.Tokens >= 1
+	if allowed {
+		b.Tokens--
+	}
+	cl.buckets[sender] = b
+	return allowed
+}
+
+// parseChatInput picks the channel for what the player typed, "/all " sends it to everyone.
+func parseChatInput(input string) (string, int32) {
+	if strings.HasPrefix(input, "/all ") {
+		return strings.TrimPrefix(input, "/all "), ChatGlobal
+	}
+	return input, ChatLocal
+}
+
+// sendChat checks a message from the client workerID and passes it on to everyone who should hear it.  Local
+// messages go out on the sender's ship, so they need one.
+func (bs *BalancerScene) sendChat(workerID string, req SendMessageRequest) SendMessageResponse {
+	if !bs.chatLimiter.Allow(workerID, time.Now()) {
+		return SendMessageResponse{Reason: "Slow down"}
+	}
+
+	sender := workerID
+	if session, ok := bs.Players[workerID]; ok {
+		sender = session.Profile.Name
+	}
+	text, err := filterChat(bs.ChatFilters, sender, req.Text)
+	if err != nil {
+		return SendMessageResponse{Reason: err.Error()}
+	}
+
+	msg := ChatMessage{Sender: sender, Text: text, Channel: req.Channel}
+	switch req.Channel {
+	case ChatLocal:
+		ship := bs.shipOf(workerID)
+		if ship == 0 {
+			return SendMessageResponse{Reason: "Nobody can hear you without a ship"}
+		}
+		bs.say(ship, cidChat, msg)
+	case ChatGlobal:
+		if bs.ChatID == 0 {
+			return SendMessageResponse{Reason: "Global chat isn't available"}
+		}
+		bs.say(bs.ChatID, cidGlobalChat, msg)
+	default:
+		return SendMessageResponse{Reason: "Unknown channel"}
+	}
+	return SendMessageResponse{Accepted: true}
+}
+
+// say sends msg out on the chat component CID of entity ID, as an event if we can, or by adding it to the component's
+// recent messages if we can't.
+func (bs *BalancerScene) say(ID sos.EntityID, CID sos.ComponentID, msg ChatMessage) {
+	bs.chatSeq++
+	msg.Seq = bs.chatSeq
+	if es, ok := interface{}(bs.spatial).(eventSender); ok {
+		es.SendEvent(ID, CID, chatSaid, msg)
+		return
+	}
+
+	recent := append(bs.chatRecent[ID], msg)
+	if len(recent) > chatHistory {
+		recent = recent[len(recent)-chatHistory:]
+	}
+	bs.chatRecent[ID] = recent
+	if CID == cidGlobalChat {
+		bs.spatial.UpdateComponent(ID, CID, GlobalChatComponent{Recent: recent})
+	} else {
+		bs.spatial.UpdateComponent(ID, CID, ChatComponent{Recent: recent})
+	}
+}
+
+// followChat keeps up with the leader's chat on entity ID, so we carry on from it if we take over.
+func (bs *BalancerScene) followChat(ID sos.EntityID, recent []ChatMessage) {
+	if bs.Leader {
+		return
+	}
+	bs.chatRecent[ID] = recent
+	if len(recent) > 0 && recent[len(recent)-1].Seq > bs.chatSeq {
+		bs.chatSeq = recent[len(recent)-1].Seq
+	}
+}
+
+// handleSendMessage answers a request written to the session entity ID, which only its player can write to.
+func (bs *BalancerScene) handleSendMessage(ID sos.EntityID, req SendMessageRequest) SendMessageResponse {
+	workerID, ok := bs.sessions[ID]
+	if !ok {
+		return SendMessageResponse{Reason: "Unknown session"}
+	}
+	return bs.sendChat(workerID, req)
+}
+
+// hearChat shows the messages in recent on entity ID we haven't heard yet.  The first time we see an entity we've
+// missed what it's already said, so we just catch up.
+func (cs *ClientScene) hearChat(ID sos.EntityID, recent []ChatMessage, first bool) {
+	heard := cs.chatHeard[ID]
+	for _, m := range recent {
+		if m.Seq <= heard {
+			continue
+		}
+		if !first {
+			cs.Chat.Push(m.String())
+		}
+		heard = m.Seq
+	}
+	cs.chatHeard[ID] = heard
+}
+
+// hearChatEvents shows any chat sent as events with op.
+func (cs *ClientScene) hearChatEvents(op sos.ComponentUpdateOp) {
+	events, ok := interface{}(op).(componentEvents)
+	if !ok {
+		return
+	}
+	for _, e := range events.Events() {
+		if m, ok := e.(ChatMessage); ok {
+			cs.Chat.Push(m.String())
+		}
+	}
+}
+
+// sendChat asks the balancer to pass on text from us.
+func (cs *ClientScene) sendChat(text string, channel int32) {
+	if cs.SessionID == 0 {
+		cs.Chat.Push("Message not sent: Not connected yet")
+		return
+	}
+	cs.SendMessage(cs.SessionID, SendMessageRequest{Text: text, Channel: channel}, func(resp SendMessageResponse, err error) {
+		if err != nil {
+			cs.Chat.Push("Message not sent: " + err.Error())
+			return
+		}
+		if !resp.Accepted {
+			cs.Chat.Push("Message not sent: " + resp.Reason)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package superspatial
+
+import (
+	"time"
+	"unicode"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+const (
+	chatLogRows = 6
+	// Messages stay on screen this long, or as long as the chat box is open.
+	chatLogLife = 10 * time.Second
+)
+
+// ChatSystem is the chat log in the bottom left, and the box to type into that opens with Enter.
+type ChatSystem struct {
+	Font *common.Font
+	PIS  *PlayerInputSystem
+	// Send passes on what the player typed.
+	Send func(text string, channel int32)
+
+	// Open is set while the player is typing, the ship is left alone until they're done.
+	Open bool
+
+	input   string
+	box     Text
+	rows    [chatLogRows]Text
+	entries []killFeedEntry
+	dirty   bool
+}
+
+// Setup builds the log and box, adding them to rs and pinning them above offset with hs.
+func (chs *ChatSystem) Setup(rs *common.RenderSystem, hs *HudSystem, offset engo.Point) {
+	engo.Input.RegisterButton("Backspace", engo.KeyBackspace)
+
+	add := func(t *Text, pos engo.Point) {
+		*t = Text{BasicEntity: ecs.NewBasic()}
+		t.RenderComponent.Drawable = common.Text{Font: chs.Font, Text: " "}
+		t.RenderComponent.SetZIndex(100)
+		t.RenderComponent.Hidden = true
+		rs.Add(&t.BasicEntity, &t.RenderComponent, &t.SpaceComponent)
+		hs.Add(&t.BasicEntity, &t.RenderComponent, &t.SpaceComponent, pos)
+	}
+	add(&chs.box, offset)
+	for i := range chs.rows {
+		// Newest message sits just above the box.
+		add(&chs.rows[i], engo.Point{X: offset.X, Y: offset.Y - float32((i+1)*28)})
+	}
+
+	engo.Mailbox.Listen(engo.TextMessage{}.Type(), func(msg engo.Message) {
+		text, ok := msg.(engo.TextMessage)
+		if !ok || !chs.Open || !unicode.IsPrint(text.Char) {
+			return
+		}
+		if len([]rune(chs.input)) < maxChatLength {
+			chs.input += string(text.Char)
+			chs.dirty = true
+		}
+	})
+}
+
+// Push adds a line to the log.
+func (chs *ChatSystem) Push(text string) {
+	chs.entries = append([]killFeedEntry{{Text: text, At: time.Now()}}, chs.entries...)
+	if len(chs.entries) > chatLogRows {
+		chs.entries = chs.entries[:chatLogRows]
+	}
+	chs.dirty = true
+}
+
+func (*ChatSystem) Remove(ecs.BasicEntity) {}
+func (chs *ChatSystem) Update(dt float32) {
+	switch {
+	case !chs.Open && !chs.PIS.Paused && engo.Input.Button("Enter").JustPressed():
+		chs.Open = true
+		chs.PIS.Paused = true
+		chs.dirty = true
+	case chs.Open && engo.Input.Button("Enter").JustPressed():
+		if text, channel := parseChatInput(chs.input); CleanChatText(text) != "" && chs.Send != nil {
+			chs.Send(text, channel)
+		}
+		chs.close()
+	case chs.Open && engo.Input.Button("Pause").JustPressed():
+		chs.close()
+	case chs.Open && engo.Input.Button("Backspace").JustPressed() && chs.input != "":
+		r := []rune(chs.input)
+		chs.input = string(r[:len(r)-1])
+		chs.dirty = true
+	}
+
+	now := time.Now()
+	for i := range chs.rows {
+		row := &chs.rows[i]
+		row.RenderComponent.Hidden = i >= len(chs.entries) || (!chs.Open && now.Sub(chs.entries[i].At) > chatLogLife)
+		if !row.RenderComponent.Hidden && chs.dirty {
+			row.RenderComponent.Drawable = common.Text{Font: chs.Font, Text: chs.entries[i].Text}
+		}
+	}
+
+	chs.box.RenderComponent.Hidden = !chs.Open
+	if chs.Open && chs.dirty {
+		chs.box.RenderComponent.Drawable = common.Text{Font: chs.Font, Text: chatPrompt(chs.input)}
+	}
+	chs.dirty = false
+}
+
+func (chs *ChatSystem) close() {
+	chs.Open = false
+	chs.PIS.Paused = false
+	chs.input = ""
+	chs.dirty = true
+}
+
+// chatPrompt is what the chat box shows while typing input.
+func chatPrompt(input string) string {
+	text, channel := parseChatInput(input)
+	if channel == ChatGlobal {
+		return "All: " + text + "_"
+	}
+	return "Say: " + text + "_"
+}
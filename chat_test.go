@@ -0,0 +1,93 @@
+package superspatial
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ScottBrooks/sos"
+)
+
+func TestWordFilter(t *testing.T) {
+	f := WordFilter("darn", " HECK ", "")
+	var tests = []struct {
+		text string
+		want string
+	}{
+		{"hello there", "hello there"},
+		{"darn it", "**** it"},
+		{"Heck, DARN darn", "****, **** ****"},
+		{"héck darn", "héck ****"},
+	}
+	for _, tt := range tests {
+		got, err := f("someone", tt.text)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", tt.text, err)
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFilterChat(t *testing.T) {
+	if _, err := filterChat(nil, "a", " \t\x00 "); err != ErrEmptyChat {
+		t.Errorf("blank message: got %v, want ErrEmptyChat", err)
+	}
+	long := strings.Repeat("a", maxChatLength+10)
+	if got, _ := filterChat(nil, "a", long); len(got) != maxChatLength {
+		t.Errorf("long message trimmed to %d, want %d", len(got), maxChatLength)
+	}
+
+	errNope := errors.New("nope")
+	reject := func(sender string, text string) (string, error) { return "", errNope }
+	if _, err := filterChat([]ChatFilter{WordFilter("x"), reject}, "a", "hi"); err != errNope {
+		t.Errorf("got %v, want the filter's error", err)
+	}
+}
+
+func TestChatLimiter(t *testing.T) {
+	var cl chatLimiter
+	now := time.Now()
+	for i := 0; i < chatBurst; i++ {
+		if !cl.Allow("a", now) {
+			t.Fatalf("message %d of the burst was limited", i)
+		}
+	}
+	if cl.Allow("a", now) {
+		t.Fatal("message after the burst was allowed")
+	}
+	if !cl.Allow("b", now) {
+		t.Fatal("another player was limited")
+	}
+	if !cl.Allow("a", now.Add(chatRefill)) {
+		t.Fatal("message after a refill was limited")
+	}
+	if cl.Allow("a", now.Add(chatRefill)) {
+		t.Fatal("refill gave more than one message")
+	}
+}
+
+func TestParseChatInput(t *testing.T) {
+	if text, channel := parseChatInput("/all gg"); text != "gg" || channel != ChatGlobal {
+		t.Errorf("got %q on %d, want gg globally", text, channel)
+	}
+	if text, channel := parseChatInput("hi /all"); text != "hi /all" || channel != ChatLocal {
+		t.Errorf("got %q on %d, want it locally", text, channel)
+	}
+}
+
+func TestHearChat(t *testing.T) {
+	cs := ClientScene{chatHeard: map[sos.EntityID]int64{}}
+	cs.hearChat(1, []ChatMessage{{Sender: "a", Text: "old", Seq: 3}}, true)
+	if len(cs.Chat.entries) != 0 {
+		t.Fatalf("replayed what was said before we got here: %+v", cs.Chat.entries)
+	}
+
+	// Two messages in one update, and one we've already heard.
+	cs.hearChat(1, []ChatMessage{{Sender: "a", Text: "old", Seq: 3}, {Sender: "a", Text: "one", Seq: 4}, {Sender: "b", Text: "two", Seq: 5}}, false)
+	if len(cs.Chat.entries) != 2 || cs.Chat.entries[0].Text != "b: two" || cs.Chat.entries[1].Text != "a: one" {
+		t.Errorf("got %+v", cs.Chat.entries)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/EngoEngine/ecs"
 	"github.com/EngoEngine/engo"
 	"github.com/EngoEngine/engo/common"
+	"github.com/go-gl/mathgl/mgl32"
 )
 
 type CircleEntity struct {
@@ -15,6 +16,8 @@ type CircleEntity struct {
 
 type CircleCollisionSystem struct {
 	Entities []CircleEntity
+	// World measures distances, the short way round when it wraps.
+	World *World
 }
 
 type CircleCollisionMessage struct {
@@ -46,7 +49,7 @@ func (ccs *CircleCollisionSystem) Update(dt float32) {
 			if a == b {
 				continue
 			}
-			dist := a.Position.PointDistance(b.Position)
+			dist := ccs.World.Distance(mgl32.Vec3{a.Position.X, a.Position.Y, 0}, mgl32.Vec3{b.Position.X, b.Position.Y, 0})
 			if dist-a.Radius-b.Radius < 0 {
 				engo.Mailbox.Dispatch(CircleCollisionMessage{
 					A: &a,
@@ -30,29 +30,75 @@ type ClientScene struct {
 	PIS       PlayerInputSystem
 	CPS       ClientPredictionSystem
 	HS        HudSystem
+	SBS       ScoreboardSystem
+	MSS       MatchScreenSystem
+	PMS       PauseMenuSystem
+	MMS       MinimapSystem
+	SSS       ShipStatusSystem
+	KFS       KillFeedSystem
+	Chat      ChatSystem
+	RS        RespawnSystem
 	HUDPos    engo.Point
 	Font      *common.Font
 	Explosion *common.Animation
 
-	EntToEcs map[sos.EntityID]uint64
-	Ships    map[sos.EntityID]*ClientShip
-	Effects  map[sos.EntityID]*ClientEffect
+	// Appearance is what the player picked in the hangar, sent once we own our ship.
+	Appearance ShipAppearanceComponent
+	Settings   ClientSettings
+	Bindings   Bindings
+	// BindingsPath is where controls rebound in game are saved, nothing is saved when it's empty.
+	BindingsPath string
+	// quitting is set once we've chosen to disconnect, so it doesn't take the whole game down.
+	quitting bool
+	// SessionID is our session entity, where we send requests to the balancer.  Unlike our ship, it sticks around
+	// when we're destroyed.
+	SessionID sos.EntityID
+
+	EntToEcs    map[sos.EntityID]uint64
+	Ships       map[sos.EntityID]*ClientShip
+	Effects     map[sos.EntityID]*ClientEffect
+	Obstacles   map[sos.EntityID]*ClientObstacle
+	Appearances map[sos.EntityID]ShipAppearanceComponent
+	Teams       map[sos.EntityID]TeamComponent
+	Players     map[sos.EntityID]PlayerComponent
+	// chatHeard is the Seq of the last chat message we've shown from each entity.
+	chatHeard map[sos.EntityID]int64
+
+	// Seam places everything around our ship, and seamTiles repeat the background past the edges, when the world wraps.
+	Seam      seamView
+	seamTiles []*Background
 }
 
 type PlayerInputSystem struct {
 	ID      sos.EntityID
 	spatial *sos.SpatialSystem
+	// Paused lets go of all the controls while a menu is open.
+	Paused   bool
+	Bindings *Bindings
+	Settings *ClientSettings
+
+	// Mouse aim needs to know where our ship is, and where the camera is looking.
+	Ships  map[sos.EntityID]*ClientShip
+	Camera *common.CameraSystem
+	mouse  mouseButtons
 }
 
 func (pis *PlayerInputSystem) Remove(ecs.BasicEntity) {}
 func (pis *PlayerInputSystem) Update(dt float32) {
 	var p PlayerInputComponent
 
-	p.Left = engo.Input.Button("Left").Down()
-	p.Right = engo.Input.Button("Right").Down()
-	p.Forward = engo.Input.Button("Up").Down()
-	p.Back = engo.Input.Button("Down").Down()
-	p.Attack = engo.Input.Button("Space").Down()
+	// Buttons pressed or let go while we're paused, or not aiming with the mouse, still count once we're back.
+	if pis.mouse == nil {
+		pis.mouse = mouseButtons{}
+	}
+	pis.mouse.update(engo.Input.Mouse)
+
+	if !pis.Paused && pis.Bindings != nil {
+		p = pis.Bindings.Read()
+		if pis.Settings != nil && pis.Settings.MouseAim {
+			pis.mouseAim(&p)
+		}
+	}
 
 	if pis.ID != 0 {
 		pis.spatial.UpdateComponent(pis.ID, cidPlayerInput, p)
@@ -74,18 +120,26 @@ type ClientShip struct {
 
 	ShipComponent
 	WorkerComponent
+	Appearance ShipAppearanceComponent
+	Team       TeamComponent
+
+	seam *seamView
 }
 
 func (cs *ClientShip) Predict(dt float32) {
 
 	// Add 50% of our new position and 50% of our old position.
 	newPos := cs.ShipComponent.Pos.Add(cs.ShipComponent.Vel.Mul(dt))
-	avgPos := cs.ShipComponent.Pos.Mul(0.5).Add(newPos.Mul(0.5))
+	avgPos := cs.seam.place(cs.ShipComponent.Pos.Mul(0.5).Add(newPos.Mul(0.5)))
 
 	cs.SpaceComponent.SetCenter(engo.Point{X: avgPos[0], Y: avgPos[1]})
 	cs.SpaceComponent.Rotation = cs.ShipComponent.Angle - 90
 	cs.text.SpaceComponent = cs.SpaceComponent
 	cs.text.SpaceComponent.Rotation = 0
+
+	// Flash while spawn protection lasts.
+	now := time.Now()
+	cs.RenderComponent.Hidden = cs.ShipComponent.Protected(now) && (now.UnixNano()/int64(protectionFlash))%2 == 0
 }
 
 type Background struct {
@@ -102,10 +156,6 @@ func (cs *ClientScene) Preload() {
 		"Ships/ship-orange.png",
 		"Ships/ship-red.png",
 		"Backgrounds/stars.png",
-		"UI/Upgrade/Health.png",
-		"UI/Loading_Bar/Loading_Bar_2_1.png",
-		"UI/Loading_Bar/Loading_Bar_2_2.png",
-		"UI/Loading_Bar/Loading_Bar_2_3.png",
 		"Ships/Explosion/explosion.png",
 	} {
 		err := engo.Files.Load(asset)
@@ -113,6 +163,12 @@ func (cs *ClientScene) Preload() {
 			panic(err)
 		}
 	}
+	for _, asset := range append(append(matchScreenAssets, pauseMenuAssets...), shipStatusAssets...) {
+		err := engo.Files.Load(asset)
+		if err != nil {
+			panic(err)
+		}
+	}
 	err := engo.Files.LoadReaderData("go.ttf", bytes.NewReader(gosmallcaps.TTF))
 	if err != nil {
 		panic(err)
@@ -123,6 +179,7 @@ func (cs *ClientScene) Preload() {
 func (cs *ClientScene) Setup(u engo.Updater) {
 
 	w, _ := u.(*ecs.World)
+	cs.quitting = false
 	var locatorParams *sos.WorkerLocatorParams
 	host := cs.ServerScene.Host
 	port := cs.ServerScene.Port
@@ -144,12 +201,23 @@ func (cs *ClientScene) Setup(u engo.Updater) {
 	cs.spatial = sos.NewSpatialSystem(cs, host, port, cs.ServerScene.WorkerID, locatorParams)
 	cs.Entities = map[sos.EntityID]interface{}{}
 	cs.OnCreateFunc = map[sos.RequestID]func(ID sos.EntityID){}
+	cs.Commands = Commands{}
 	cs.EntToEcs = map[sos.EntityID]uint64{}
 	cs.Ships = map[sos.EntityID]*ClientShip{}
 	cs.Effects = map[sos.EntityID]*ClientEffect{}
+	cs.Obstacles = map[sos.EntityID]*ClientObstacle{}
+	cs.Appearances = map[sos.EntityID]ShipAppearanceComponent{}
+	cs.Teams = map[sos.EntityID]TeamComponent{}
+	cs.Players = map[sos.EntityID]PlayerComponent{}
+	cs.chatHeard = map[sos.EntityID]int64{}
+	cs.setupWorld(worldBounds)
+	cs.Seam = seamView{World: &cs.World}
 	cs.Explosion = &common.Animation{Name: "explosion", Frames: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}}
 
 	cs.PIS.spatial = cs.ServerScene.spatial
+	cs.PIS.Bindings = &cs.Bindings
+	cs.PIS.Settings = &cs.Settings
+	cs.PIS.Ships = cs.Ships
 
 	w.AddSystem(&cs.R)
 	w.AddSystem(&cs.PIS)
@@ -161,6 +229,7 @@ func (cs *ClientScene) Setup(u engo.Updater) {
 		case *common.CameraSystem:
 			log.Printf("Found a camera system: %+v", ent)
 			cs.CS = ent
+			cs.PIS.Camera = ent
 		}
 	}
 
@@ -177,6 +246,53 @@ func (cs *ClientScene) Setup(u engo.Updater) {
 	cs.HS = HudSystem{Pos: &cs.HUDPos, Camera: cs.CS}
 	w.AddSystem(&cs.HS)
 
+	cs.SBS = ScoreboardSystem{Font: cs.Font, Text: Text{BasicEntity: ecs.NewBasic()}}
+	cs.SBS.Text.RenderComponent.Drawable = common.Text{Font: cs.Font, Text: formatLeaderboard(LeaderboardComponent{})}
+	cs.SBS.Text.RenderComponent.SetZIndex(100)
+	cs.SBS.Text.RenderComponent.Hidden = true
+	cs.R.Add(&cs.SBS.Text.BasicEntity, &cs.SBS.Text.RenderComponent, &cs.SBS.Text.SpaceComponent)
+	cs.HS.Add(&cs.SBS.Text.BasicEntity, &cs.SBS.Text.RenderComponent, &cs.SBS.Text.SpaceComponent, engo.Point{X: -300, Y: -250})
+	cs.SBS.Teams = Text{BasicEntity: ecs.NewBasic()}
+	cs.SBS.Teams.RenderComponent.Drawable = common.Text{Font: cs.Font, Text: formatTeamScores(nil)}
+	cs.SBS.Teams.RenderComponent.SetZIndex(100)
+	cs.SBS.Teams.RenderComponent.Hidden = true
+	cs.R.Add(&cs.SBS.Teams.BasicEntity, &cs.SBS.Teams.RenderComponent, &cs.SBS.Teams.SpaceComponent)
+	cs.HS.Add(&cs.SBS.Teams.BasicEntity, &cs.SBS.Teams.RenderComponent, &cs.SBS.Teams.SpaceComponent, engo.Point{X: -100, Y: -370})
+	w.AddSystem(&cs.SBS)
+
+	cs.MSS = MatchScreenSystem{Font: cs.Font}
+	cs.MSS.Setup(&cs.R, &cs.HS)
+	w.AddSystem(&cs.MSS)
+
+	cs.MMS = MinimapSystem{CS: cs, Offset: engo.Point{X: 300, Y: -370}}
+	cs.MMS.Setup()
+	w.AddSystem(&cs.MMS)
+
+	cs.SSS = ShipStatusSystem{CS: cs}
+	cs.SSS.Setup()
+	w.AddSystem(&cs.SSS)
+
+	cs.KFS = KillFeedSystem{Font: cs.Font}
+	cs.KFS.Setup(&cs.R, &cs.HS, engo.Point{X: 200, Y: -250})
+	w.AddSystem(&cs.KFS)
+
+	if cs.Settings == (ClientSettings{}) {
+		cs.Settings = DefaultClientSettings
+	}
+	cs.PMS = PauseMenuSystem{Font: cs.Font, PIS: &cs.PIS, Bindings: &cs.Bindings, Settings: &cs.Settings, OnSettingsChanged: cs.applySettings, OnBindingsChanged: cs.saveBindings, OnQuit: cs.quitToMenu, Chat: &cs.Chat}
+	cs.PMS.Setup(&cs.R, &cs.HS)
+	w.AddSystem(&cs.PMS)
+	cs.applySettings()
+
+	// After the pause menu, so it sees the chat box is open before Escape closes it.
+	cs.Chat = ChatSystem{Font: cs.Font, PIS: &cs.PIS, Send: cs.sendChat}
+	cs.Chat.Setup(&cs.R, &cs.HS, engo.Point{X: -300, Y: 330})
+	w.AddSystem(&cs.Chat)
+
+	cs.RS = RespawnSystem{Font: cs.Font, PIS: &cs.PIS, Bindings: &cs.Bindings, Send: cs.requestRespawn}
+	cs.RS.Setup(&cs.R, &cs.HS, engo.Point{X: -150, Y: 0})
+	w.AddSystem(&cs.RS)
+
 	backgroundImage, err := common.LoadedSprite("Backgrounds/stars.png")
 	if err != nil {
 		log.Printf("Unable to load background image: %+v", err)
@@ -194,41 +310,66 @@ func (cs *ClientScene) Setup(u engo.Updater) {
 		},
 	}
 	bg.SetZIndex(0)
-
-	cs.Camera.TrackingBounds = worldBounds
 	cs.R.Add(&bg.BasicEntity, &bg.RenderComponent, &bg.SpaceComponent)
+
+	// Copies of the background all the way round, shown when the world wraps so there's no edge to see.
+	cs.seamTiles = nil
+	for x := -1; x <= 1; x++ {
+		for y := -1; y <= 1; y++ {
+			if x == 0 && y == 0 {
+				continue
+			}
+			tile := &Background{BasicEntity: ecs.NewBasic(), RenderComponent: bg.RenderComponent, SpaceComponent: bg.SpaceComponent}
+			tile.SpaceComponent.Position = engo.Point{X: float32(x) * worldBounds.Max.X, Y: float32(y) * worldBounds.Max.Y}
+			cs.R.Add(&tile.BasicEntity, &tile.RenderComponent, &tile.SpaceComponent)
+			cs.seamTiles = append(cs.seamTiles, tile)
+		}
+	}
+	cs.applyTopology()
 	w.AddSystem(&cs.Camera)
 
 	cs.HUDPos.Set(0, 0)
 
 	engo.Mailbox.Listen(DeleteEntityMessage{}.Type(), func(m engo.Message) {
-		delete, ok := m.(DeleteEntityMessage)
-		log.Printf("Got delete message: %+v", delete)
+		dem, ok := m.(DeleteEntityMessage)
+		log.Printf("Got delete message: %+v", dem)
 		if ok {
-			log.Printf("Ent: %+v", cs.Entities[delete.ID])
-			ship := cs.Ships[delete.ID]
+			log.Printf("Ent: %+v", cs.Entities[dem.ID])
+			if dem.ID == cs.PIS.ID {
+				cs.PIS.ID = 0
+				cs.RS.Died()
+			}
+			ship := cs.Ships[dem.ID]
 			if ship != nil {
 				w.RemoveEntity(ship.BasicEntity)
 				w.RemoveEntity(ship.text.BasicEntity)
+				delete(cs.Ships, dem.ID)
 			}
-			effect := cs.Effects[delete.ID]
+			effect := cs.Effects[dem.ID]
 			if effect != nil {
 				w.RemoveEntity(effect.BasicEntity)
+				delete(cs.Effects, dem.ID)
+			}
+			obstacle := cs.Obstacles[dem.ID]
+			if obstacle != nil {
+				w.RemoveEntity(obstacle.BasicEntity)
+				delete(cs.Obstacles, dem.ID)
 			}
 		}
 	})
 
 }
 
-func (cs *ClientScene) NewShip(s *ShipComponent) *ClientShip {
+func (cs *ClientScene) NewShip(s *ShipComponent, appearance ShipAppearanceComponent, team TeamComponent) *ClientShip {
 
-	ship := ClientShip{BasicEntity: ecs.NewBasic()}
-	texture, err := common.LoadedSprite("Ships/ship-aqua.png")
+	ship := ClientShip{BasicEntity: ecs.NewBasic(), Appearance: appearance, Team: team, seam: &cs.Seam}
+	texture, err := common.LoadedSprite(HullSprite(ShipHull(appearance, team)))
 	if err != nil {
 		log.Printf("UNable to load texture: %+v", err)
 	}
 
-	spawnPoint := engo.Point{X: s.Pos[0], Y: s.Pos[1]}
+	pos := cs.Seam.place(s.Pos)
+	spawnPoint := engo.Point{X: pos[0], Y: pos[1]}
 
 	ship.RenderComponent = common.RenderComponent{
 		Drawable: texture,
@@ -241,10 +382,7 @@ func (cs *ClientScene) NewShip(s *ShipComponent) *ClientShip {
 	}
 
 	ship.text = Text{BasicEntity: ecs.NewBasic()}
-	ship.text.RenderComponent.Drawable = common.Text{
-		Font: cs.Font,
-		Text: "Ship",
-	}
+	cs.updateShipText(&ship)
 	ship.text.SpaceComponent = ship.SpaceComponent
 	ship.text.RenderComponent.SetZIndex(11)
 
@@ -260,6 +398,157 @@ func (cs *ClientScene) NewShip(s *ShipComponent) *ClientShip {
 	return &ship
 }
 
+// updateShipText labels a ship with its name and the worker simulating it.
+func (cs *ClientScene) updateShipText(ship *ClientShip) {
+	// Owners write their own appearance, so whatever they put there needs cleaning up before anyone else sees it.
+	name := CleanShipName(ship.Appearance.Name)
+	if name == "" {
+		name = "Ship"
+	}
+	if cs.Settings.ShowWorkerIDs {
+		name = fmt.Sprintf("%s [%d]", name, ship.WorkerComponent.WorkerID)
+	}
+	ship.text.RenderComponent.Drawable = common.Text{
+		Font: cs.Font,
+		Text: name,
+	}
+}
+
+// applyTopology shows the world the way its edges work, with walls at the edge of the map or the map repeating past
+// them.  The camera can follow us past the edge when there's more of the world to see there.
+func (cs *ClientScene) applyTopology() {
+	wraps := cs.World.Wraps()
+	for _, tile := range cs.seamTiles {
+		tile.RenderComponent.Hidden = !wraps
+	}
+
+	cs.Camera.TrackingBounds = worldBounds
+	if wraps {
+		cs.Camera.TrackingBounds = engo.AABB{
+			Min: engo.Point{X: worldBounds.Min.X - worldBounds.Max.X, Y: worldBounds.Min.Y - worldBounds.Max.Y},
+			Max: engo.Point{X: worldBounds.Max.X * 2, Y: worldBounds.Max.Y * 2},
+		}
+	}
+}
+
+func (cs *ClientScene) OnFlagUpdate(op sos.FlagUpdateOp) {
+	cs.ServerScene.OnFlagUpdate(op)
+	if op.Key == "WORLD_TOPOLOGY" {
+		cs.applyTopology()
+	}
+}
+
+// applySettings makes changes from the settings screen take effect.
+func (cs *ClientScene) applySettings() {
+	cs.HS.Scale = cs.Settings.UIScale
+	for _, ship := range cs.Ships {
+		cs.updateShipText(ship)
+	}
+}
+
+// quitToMenu disconnects from spatial and heads back to the main menu.  Everything is set up from scratch if we start
+// playing again.
+func (cs *ClientScene) quitToMenu() {
+	log.Printf("Quitting to menu")
+	cs.quitting = true
+	if d, ok := interface{}(cs.spatial).(disconnecter); ok {
+		d.Disconnect()
+	} else {
+		// Our connection stays up until we exit, the least we can do is stop flying.
+		log.Printf("Unable to disconnect, leaving our ship where it is")
+		if cs.PIS.ID != 0 {
+			cs.spatial.UpdateComponent(cs.PIS.ID, cidPlayerInput, PlayerInputComponent{})
+		}
+	}
+	cs.reset()
+
+	if err := engo.SetSceneByName("Menu", true); err != nil {
+		log.Printf("No menu to go back to, exiting: %v", err)
+		engo.Exit()
+	}
+}
+
+// reset throws away everything from our last connection.
+func (cs *ClientScene) reset() {
+	cs.spatial = nil
+	cs.Commands = Commands{}
+	cs.PIS = PlayerInputSystem{}
+	cs.CPS = ClientPredictionSystem{}
+	cs.R = common.RenderSystem{}
+	cs.Anim = common.AnimationSystem{}
+	cs.Camera = common.EntityScroller{}
+	cs.HUDPos = engo.Point{}
+	cs.Match = MatchComponent{}
+	cs.SessionID = 0
+	cs.Entities = nil
+	cs.Ships = nil
+	cs.Effects = nil
+	cs.Obstacles = nil
+	cs.Appearances = nil
+	cs.Teams = nil
+	cs.Players = nil
+	cs.chatHeard = nil
+	cs.EntToEcs = nil
+	cs.seamTiles = nil
+}
+
+func (cs *ClientScene) OnDisconnect(op sos.DisconnectOp) {
+	if cs.quitting {
+		return
+	}
+	cs.ServerScene.OnDisconnect(op)
+}
+
+// saveBindings keeps controls rebound from the settings screen for next time.
+func (cs *ClientScene) saveBindings() {
+	if cs.BindingsPath == "" {
+		return
+	}
+	if err := SaveBindings(cs.BindingsPath, cs.Bindings); err != nil {
+		log.Printf("Unable to save controls to %s: %v", cs.BindingsPath, err)
+	}
+}
+
+// setAppearance changes the hull and name of the ship for entity ID, or remembers it for when the ship shows up.
+func (cs *ClientScene) setAppearance(ID sos.EntityID, appearance ShipAppearanceComponent) {
+	cs.Appearances[ID] = appearance
+
+	ship, ok := cs.Ships[ID]
+	if !ok || ship.Appearance == appearance {
+		return
+	}
+	ship.Appearance = appearance
+	cs.updateShipHull(ship)
+	cs.updateShipText(ship)
+}
+
+// setTeam moves the ship for entity ID onto team, or remembers it for when the ship shows up.
+func (cs *ClientScene) setTeam(ID sos.EntityID, team TeamComponent) {
+	cs.Teams[ID] = team
+
+	ship, ok := cs.Ships[ID]
+	if !ok || ship.Team == team {
+		return
+	}
+	ship.Team = team
+	cs.updateShipHull(ship)
+}
+
+func (cs *ClientScene) updateShipHull(ship *ClientShip) {
+	texture, err := common.LoadedSprite(HullSprite(ShipHull(ship.Appearance, ship.Team)))
+	if err != nil {
+		log.Printf("UNable to load texture: %+v", err)
+		return
+	}
+	ship.RenderComponent.Drawable = texture
+}
+
+// setMatch shows how the match is going, and whether our ship won once it's over.
+func (cs *ClientScene) setMatch(m MatchComponent) {
+	cs.Match = m
+	cs.MSS.SetMatch(m, m.Won(cs.Players[cs.PIS.ID].PlayerID, cs.Teams[cs.PIS.ID]))
+}
+
 func (cs *ClientScene) NewEffect(e *EffectComponent) *ClientEffect {
 	log.Printf("Got a new effect: %v", e)
 
@@ -270,6 +559,8 @@ func (cs *ClientScene) NewEffect(e *EffectComponent) *ClientEffect {
 	effect.AnimationComponent.AddDefaultAnimation(cs.Explosion)
 	effect.EffectComponent = *e
 
+	// Effects don't move, so they stay on whichever side of the seam they first showed up.
+	pos := cs.Seam.place(e.Pos)
 	switch e.Id {
 	case 1:
 		effect.RenderComponent = common.RenderComponent{
@@ -277,11 +568,11 @@ func (cs *ClientScene) NewEffect(e *EffectComponent) *ClientEffect {
 			Scale:    engo.Point{X: 1, Y: 1},
 		}
 		effect.SpaceComponent = common.SpaceComponent{
-			Position: engo.Point{X: e.Pos[0], Y: e.Pos[1]},
+			Position: engo.Point{X: pos[0], Y: pos[1]},
 			Width:    128 * effect.RenderComponent.Scale.X,
 			Height:   128 * effect.RenderComponent.Scale.Y,
 		}
-		effect.SpaceComponent.SetCenter(engo.Point{X: e.Pos[0], Y: e.Pos[1]})
+		effect.SpaceComponent.SetCenter(engo.Point{X: pos[0], Y: pos[1]})
 		// Effects go slightly behind ships
 		effect.RenderComponent.SetZIndex(9)
 
@@ -296,28 +587,41 @@ func (cs *ClientScene) NewEffect(e *EffectComponent) *ClientEffect {
 
 func (cs *ClientScene) OnComponentUpdate(op sos.ComponentUpdateOp) {
 	cs.ServerScene.OnComponentUpdate(op)
+	cs.hearChatEvents(op)
 
 	switch c := op.Component.(type) {
 	case *ShipComponent:
-		ship := cs.Ships[op.ID]
+		ship, ok := cs.Ships[op.ID]
+		if !ok {
+			return
+		}
 		ship.ShipComponent = *c
 		if op.ID == cs.PIS.ID {
-			ship, ok := cs.Ships[op.ID]
-			if ok {
-				cs.Camera.SpaceComponent = &ship.SpaceComponent
-			}
+			cs.Camera.SpaceComponent = &ship.SpaceComponent
+			cs.Seam.Focus = c.Pos
 		}
+	case *LeaderboardComponent:
+		cs.SBS.SetLeaderboard(*c)
 	case *WorkerComponent:
 		ship, ok := cs.Ships[op.ID]
 		if ok {
 			if ship.WorkerComponent.WorkerID != c.WorkerID {
-				ship.text.RenderComponent.Drawable = common.Text{
-					Font: cs.Font,
-					Text: fmt.Sprintf("Worker: %d", c.WorkerID),
-				}
+				ship.WorkerComponent = *c
+				cs.updateShipText(ship)
 			}
-			ship.WorkerComponent = *c
 		}
+	case *ShipAppearanceComponent:
+		cs.setAppearance(op.ID, *c)
+	case *TeamComponent:
+		cs.setTeam(op.ID, *c)
+	case *PlayerComponent:
+		cs.Players[op.ID] = *c
+	case *MatchComponent:
+		cs.setMatch(*c)
+	case *ChatComponent:
+		cs.hearChat(op.ID, c.Recent, false)
+	case *GlobalChatComponent:
+		cs.hearChat(op.ID, c.Recent, false)
 	}
 }
 
@@ -327,9 +631,31 @@ func (cs *ClientScene) OnAddComponent(op sos.AddComponentOp) {
 
 	switch c := op.Component.(type) {
 	case *ShipComponent:
-		ship := cs.NewShip(c)
+		ship := cs.NewShip(c, cs.Appearances[op.ID], cs.Teams[op.ID])
 		cs.EntToEcs[op.ID] = ship.ID()
 		cs.Ships[op.ID] = ship
+	case *ShipAppearanceComponent:
+		cs.setAppearance(op.ID, *c)
+	case *TeamComponent:
+		cs.setTeam(op.ID, *c)
+	case *PlayerComponent:
+		cs.Players[op.ID] = *c
+	case *MatchComponent:
+		cs.setMatch(*c)
+	case *LeaderboardComponent:
+		cs.SBS.SetLeaderboard(*c)
+	case *NotificationComponent:
+		cs.KFS.Push(c.String())
+	case *ChatComponent:
+		cs.hearChat(op.ID, c.Recent, true)
+	case *GlobalChatComponent:
+		cs.hearChat(op.ID, c.Recent, true)
+	case *ObstacleComponent:
+		if _, ok := cs.Obstacles[op.ID]; !ok {
+			obstacle := cs.NewObstacle(c)
+			cs.EntToEcs[op.ID] = obstacle.ID()
+			cs.Obstacles[op.ID] = obstacle
+		}
 	case *EffectComponent:
 		_, hasEffect := cs.EntToEcs[op.ID]
 		if !hasEffect {
@@ -343,6 +669,7 @@ func (cs *ClientScene) OnAddComponent(op sos.AddComponentOp) {
 
 func (cs *ClientScene) OnRemoveEntity(op sos.RemoveEntityOp) {
 	cs.ServerScene.OnRemoveEntity(op)
+	delete(cs.chatHeard, op.ID)
 
 	engo.Mailbox.Dispatch(DeleteEntityMessage{ID: op.ID})
 }
@@ -356,6 +683,24 @@ func (cs *ClientScene) OnAuthorityChange(op sos.AuthorityChangeOp) {
 	log.Printf("AUthChanged: %+v", op)
 	if op.CID == cidPlayerInput && op.Authority == 1 {
 		cs.PIS.ID = op.ID
+		cs.RS.Spawned()
+	}
+	if op.CID == cidSendMessageQueue && op.Authority == 1 {
+		cs.SessionID = op.ID
+	}
+	// Our new ship starts with whatever the balancer remembered, tell it what we picked in the hangar.
+	if op.CID == cidShipAppearance && op.Authority == 1 {
+		appearance := cs.Appearances[op.ID]
+		if ValidHull(cs.Appearance.Hull) {
+			appearance.Hull = cs.Appearance.Hull
+		}
+		if name := CleanShipName(cs.Appearance.Name); name != "" {
+			appearance.Name = name
+		}
+		if appearance != cs.Appearances[op.ID] {
+			cs.spatial.UpdateComponent(op.ID, cidShipAppearance, appearance)
+			cs.setAppearance(op.ID, appearance)
+		}
 	}
 }
 
@@ -2,7 +2,10 @@ package main
 
 import (
 	"flag"
+	"io/ioutil"
+	"log"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/EngoEngine/engo"
@@ -15,6 +18,9 @@ func main() {
 	port := flag.Int("port", 7777, "receptionist port")
 	workerID := flag.String("worker", "", "worker ID")
 	development := flag.Bool("dev", true, "set to false if to try to fork ./server")
+	profiles := flag.String("profiles", "profiles", "directory to save player profiles in, empty to not save them")
+	chatWords := flag.String("chatwords", "", "file of words, one per line, to mask out of chat")
+	topology := flag.String("topology", "", "world edges: bounded or wrap (defaults to the WORLD_TOPOLOGY worker flag)")
 	flag.Parse()
 
 	opts := engo.RunOptions{
@@ -22,7 +28,17 @@ func main() {
 		HeadlessMode: true,
 		FPSLimit:     30,
 	}
-	ss := superspatial.BalancerScene{WorldBounds: engo.AABB{Max: engo.Point{2048, 1024}}, ServerScene: superspatial.ServerScene{WorkerTypeName: "Balancer", Host: *host, Port: *port, WorkerID: *workerID, Development: *development}}
+	ss := superspatial.BalancerScene{WorldBounds: engo.AABB{Max: engo.Point{2048, 1024}}, ServerScene: superspatial.ServerScene{WorkerTypeName: "Balancer", Host: *host, Port: *port, WorkerID: *workerID, Development: *development, Topology: *topology}}
+	if *profiles != "" {
+		ss.Profiles = &superspatial.FileProfileStore{Dir: *profiles}
+	}
+	if *chatWords != "" {
+		data, err := ioutil.ReadFile(*chatWords)
+		if err != nil {
+			log.Fatalf("Unable to read chat words: %v", err)
+		}
+		ss.ChatFilters = append(ss.ChatFilters, superspatial.WordFilter(strings.Split(string(data), "\n")...))
+	}
 
 	engo.Run(opts, &ss)
 }
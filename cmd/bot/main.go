@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/EngoEngine/engo"
@@ -15,6 +16,8 @@ func main() {
 	port := flag.Int("port", 7777, "receptionist port")
 	workerID := flag.String("worker", "", "worker ID")
 	development := flag.Bool("dev", true, "set to false if to try to fork ./server")
+	brain := flag.String("brain", "", "bot behaviour: "+strings.Join(superspatial.BotBrainNames(), ", ")+", or a behaviour tree .json file (defaults to the BOT_BRAIN worker flag)")
+	topology := flag.String("topology", "", "world edges: bounded or wrap (defaults to the WORLD_TOPOLOGY worker flag)")
 	flag.Parse()
 
 	opts := engo.RunOptions{
@@ -22,7 +25,7 @@ func main() {
 		HeadlessMode: true,
 		FPSLimit:     30,
 	}
-	ss := superspatial.BotScene{ServerScene: superspatial.ServerScene{WorkerTypeName: "Bot", Host: *host, Port: *port, WorkerID: *workerID, Development: *development}}
+	ss := superspatial.BotScene{ServerScene: superspatial.ServerScene{WorkerTypeName: "Bot", Host: *host, Port: *port, WorkerID: *workerID, Development: *development, Topology: *topology}, Brain: *brain}
 
 	engo.Run(opts, &ss)
 }
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"image/color"
+	"log"
+	"unicode"
+
+	"github.com/ScottBrooks/superspatial"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+// HangarScene lets the player pick their hull colour and name before playing.
+type HangarScene struct {
+	Appearance *superspatial.ShipAppearanceComponent
+
+	Font    *common.Font
+	preview MenuSprite
+	hull    Text
+	name    Text
+}
+
+func (*HangarScene) Preload() {
+	assets := []string{
+		"UI/Hangar/Window.png",
+		"UI/Hangar/Header.png",
+	}
+	for _, hull := range superspatial.ShipHulls {
+		assets = append(assets, superspatial.HullSprite(hull))
+	}
+	for _, asset := range assets {
+		err := engo.Files.Load(asset)
+		if err != nil {
+			log.Fatalf("Error loading asset: %v", err)
+		}
+	}
+}
+
+func (*HangarScene) Type() string { return "Hangar" }
+
+func (hs *HangarScene) Setup(u engo.Updater) {
+	w, _ := u.(*ecs.World)
+
+	engo.Input.RegisterButton("Backspace", engo.KeyBackspace)
+
+	common.SetBackground(color.Black)
+	rs := common.RenderSystem{}
+	ss := superspatial.SelectionSystem{}
+	w.AddSystem(&rs)
+	w.AddSystem(&ss)
+	w.AddSystem(hs)
+
+	if !superspatial.ValidHull(hs.Appearance.Hull) {
+		hs.Appearance.Hull = superspatial.ShipHulls[0]
+	}
+
+	hs.Font = &common.Font{
+		URL:  "go.ttf",
+		FG:   color.White,
+		Size: 32,
+	}
+	hs.Font.CreatePreloaded()
+
+	window := MenuSprite{
+		BasicEntity: ecs.NewBasic(),
+		RenderComponent: common.RenderComponent{
+			Drawable: mustLoadSprite("UI/Hangar/Window.png"),
+			Scale:    engo.Point{0.5, 0.5},
+		},
+		SpaceComponent: common.SpaceComponent{
+			Position: engo.Point{277, 34},
+			Width:    470,
+			Height:   700,
+		},
+	}
+	header := MenuSprite{
+		BasicEntity: ecs.NewBasic(),
+		RenderComponent: common.RenderComponent{
+			Drawable:    mustLoadSprite("UI/Hangar/Header.png"),
+			Scale:       engo.Point{1, 1},
+			StartZIndex: 1,
+		},
+		SpaceComponent: common.SpaceComponent{
+			Position: engo.Point{316, 60},
+			Width:    391,
+			Height:   60,
+		},
+	}
+	hs.preview = MenuSprite{
+		BasicEntity: ecs.NewBasic(),
+		RenderComponent: common.RenderComponent{
+			Drawable:    mustLoadSprite(superspatial.HullSprite(hs.Appearance.Hull)),
+			Scale:       engo.Point{2, 2},
+			StartZIndex: 1,
+		},
+		SpaceComponent: common.SpaceComponent{
+			Position: engo.Point{448, 180},
+			Width:    128,
+			Height:   128,
+		},
+	}
+	hs.hull = hs.newText(engo.Point{350, 380})
+	hs.name = hs.newText(engo.Point{350, 460})
+	done := hs.newText(engo.Point{350, 580})
+	done.Drawable = common.Text{Font: hs.Font, Text: "Done"}
+	hs.refresh()
+
+	for _, s := range []*MenuSprite{&window, &header, &hs.preview} {
+		rs.Add(&s.BasicEntity, &s.RenderComponent, &s.SpaceComponent)
+	}
+	for _, t := range []*Text{&hs.hull, &hs.name, &done} {
+		rs.Add(&t.BasicEntity, &t.RenderComponent, &t.SpaceComponent)
+	}
+
+	ss.Add(&hs.hull.BasicEntity, &hs.hull.RenderComponent, func() {
+		hs.Appearance.Hull = superspatial.NextHull(hs.Appearance.Hull)
+		hs.refresh()
+	})
+	// Typing always edits the name, Enter on it does nothing.
+	ss.Add(&hs.name.BasicEntity, &hs.name.RenderComponent, func() {})
+	ss.Add(&done.BasicEntity, &done.RenderComponent, func() {
+		ss.Reset()
+		hs.Appearance.Name = superspatial.CleanShipName(hs.Appearance.Name)
+		engo.SetSceneByName("Menu", true)
+	})
+
+	engo.Mailbox.Listen(engo.TextMessage{}.Type(), func(msg engo.Message) {
+		text, ok := msg.(engo.TextMessage)
+		if !ok || !unicode.IsPrint(text.Char) {
+			return
+		}
+		if len([]rune(hs.Appearance.Name)) < 16 {
+			hs.Appearance.Name += string(text.Char)
+			hs.refresh()
+		}
+	})
+}
+
+func (hs *HangarScene) newText(pos engo.Point) Text {
+	return Text{
+		BasicEntity: ecs.NewBasic(),
+		RenderComponent: common.RenderComponent{
+			Scale:       engo.Point{1, 1},
+			StartZIndex: 2,
+		},
+		SpaceComponent: common.SpaceComponent{
+			Position: pos,
+			Width:    320,
+			Height:   40,
+		},
+	}
+}
+
+// refresh redraws the preview and labels after a change.
+func (hs *HangarScene) refresh() {
+	hs.preview.Drawable = mustLoadSprite(superspatial.HullSprite(hs.Appearance.Hull))
+	hs.hull.Drawable = common.Text{Font: hs.Font, Text: "Hull: " + hs.Appearance.Hull}
+
+	name := hs.Appearance.Name
+	if name == "" {
+		name = "_"
+	}
+	hs.name.Drawable = common.Text{Font: hs.Font, Text: "Name: " + name}
+}
+
+func (hs *HangarScene) Remove(ecs.BasicEntity) {}
+func (hs *HangarScene) Update(dt float32) {
+	if engo.Input.Button("Backspace").JustPressed() && hs.Appearance.Name != "" {
+		r := []rune(hs.Appearance.Name)
+		hs.Appearance.Name = string(r[:len(r)-1])
+		hs.refresh()
+	}
+}
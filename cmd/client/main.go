@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/ScottBrooks/superspatial"
@@ -26,7 +27,8 @@ var (
 )
 
 type MainMenuScene struct {
-	Font *common.Font
+	Font     *common.Font
+	Bindings superspatial.Bindings
 }
 
 type Text struct {
@@ -52,12 +54,7 @@ func mustLoadSprite(sprite string) *common.Texture {
 func (mm *MainMenuScene) Setup(u engo.Updater) {
 	w, _ := u.(*ecs.World)
 
-	engo.Input.RegisterButton("Up", engo.KeyArrowUp)
-	engo.Input.RegisterButton("Down", engo.KeyArrowDown)
-	engo.Input.RegisterButton("Left", engo.KeyArrowLeft)
-	engo.Input.RegisterButton("Right", engo.KeyArrowRight)
-	engo.Input.RegisterButton("Enter", engo.KeyEnter)
-	engo.Input.RegisterButton("Space", engo.KeySpace)
+	mm.Bindings.Register()
 
 	common.SetBackground(color.White)
 	rs := common.RenderSystem{}
@@ -94,6 +91,19 @@ func (mm *MainMenuScene) Setup(u engo.Updater) {
 			Height:   121.0,
 		},
 	}
+	hangarBut := MenuSprite{
+		BasicEntity: ecs.NewBasic(),
+		RenderComponent: common.RenderComponent{
+			Drawable:    mustLoadSprite("UI/Buttons/BTNs/Hangar_BTN.png"),
+			Scale:       engo.Point{0.5, 0.5},
+			StartZIndex: 100,
+		},
+		SpaceComponent: common.SpaceComponent{
+			Position: engo.Point{840, 326},
+			Width:    105.0,
+			Height:   105.0,
+		},
+	}
 	exitBut := MenuSprite{
 		BasicEntity: ecs.NewBasic(),
 		RenderComponent: common.RenderComponent{
@@ -126,6 +136,7 @@ func (mm *MainMenuScene) Setup(u engo.Updater) {
 
 	rs.Add(&bg.BasicEntity, &bg.RenderComponent, &bg.SpaceComponent)
 	rs.Add(&startBut.BasicEntity, &startBut.RenderComponent, &startBut.SpaceComponent)
+	rs.Add(&hangarBut.BasicEntity, &hangarBut.RenderComponent, &hangarBut.SpaceComponent)
 	rs.Add(&exitBut.BasicEntity, &exitBut.RenderComponent, &exitBut.SpaceComponent)
 	rs.Add(&title.BasicEntity, &title.RenderComponent, &title.SpaceComponent)
 
@@ -133,6 +144,10 @@ func (mm *MainMenuScene) Setup(u engo.Updater) {
 		ss.Reset()
 		engo.SetSceneByName("Client", true)
 	})
+	ss.Add(&hangarBut.BasicEntity, &hangarBut.RenderComponent, func() {
+		ss.Reset()
+		engo.SetSceneByName("Hangar", true)
+	})
 	ss.Add(&exitBut.BasicEntity, &exitBut.RenderComponent, func() {
 		engo.Exit()
 	})
@@ -143,6 +158,7 @@ func (*MainMenuScene) Preload() {
 		"UI/Main_Menu/BG.png",
 		"UI/Main_Menu/Start_BTN.png",
 		"UI/Main_Menu/Exit_BTN.png",
+		"UI/Buttons/BTNs/Hangar_BTN.png",
 	}
 	engo.Files.LoadReaderData("go.ttf", bytes.NewReader(gosmallcaps.TTF))
 	for _, asset := range assets {
@@ -181,8 +197,14 @@ func main() {
 	host := flag.String("host", "127.0.0.1", "receptionist host address")
 	port := flag.Int("port", 7777, "receptionist port")
 	workerID := flag.String("worker", "", "worker ID")
+	name := flag.String("name", "", "ship name, can also be set in the hangar")
+	hull := flag.String("hull", "", "hull colour("+strings.Join(superspatial.ShipHulls, ", ")+"), can also be set in the hangar")
+	controls := flag.String("controls", "", "key bindings file, defaults to controls.json in your config directory")
+	topology := flag.String("topology", "", "world edges: bounded or wrap (defaults to the WORLD_TOPOLOGY worker flag)")
 	flag.Parse()
 
+	bindings, bindingsPath := loadBindings(*controls)
+
 	rand.Seed(time.Now().Unix())
 	var useGraphics bool
 	displayEnv := os.Getenv("DISPLAY")
@@ -190,7 +212,10 @@ func main() {
 		useGraphics = true
 	}
 
-	cs := superspatial.ClientScene{ServerScene: superspatial.ServerScene{WorkerTypeName: "LauncherClient", Host: *host, Port: *port, WorkerID: *workerID, Locator: *locator, PIT: *pit, LT: *lt, ProjectName: *project}}
+	cs := superspatial.ClientScene{ServerScene: superspatial.ServerScene{WorkerTypeName: "LauncherClient", Host: *host, Port: *port, WorkerID: *workerID, Locator: *locator, PIT: *pit, LT: *lt, ProjectName: *project, Topology: *topology}}
+	cs.Appearance = superspatial.ShipAppearanceComponent{Hull: *hull, Name: *name}
+	cs.Bindings = bindings
+	cs.BindingsPath = bindingsPath
 
 	opts := engo.RunOptions{
 		Title:          "SuperSpatial",
@@ -201,10 +226,36 @@ func main() {
 		FPSLimit:       30,
 	}
 	engo.RegisterScene(&cs)
+	engo.RegisterScene(&HangarScene{Appearance: &cs.Appearance})
 
 	if useGraphics {
-		engo.Run(opts, &MainMenuScene{})
+		engo.Run(opts, &MainMenuScene{Bindings: bindings})
 	} else {
 		engo.Run(opts, &cs)
 	}
 }
+
+// loadBindings loads the player's key bindings from path, or their config directory when path is empty, and returns
+// where they should be saved.  The first time we write out the defaults so there's something to edit.
+func loadBindings(path string) (superspatial.Bindings, string) {
+	if path == "" {
+		p, err := superspatial.BindingsPath()
+		if err != nil {
+			log.Printf("Can't find a config directory, using default controls: %v", err)
+			return superspatial.DefaultBindings, ""
+		}
+		path = p
+	}
+
+	bindings, err := superspatial.LoadBindings(path)
+	if err != nil {
+		log.Printf("Error loading controls, using defaults: %v", err)
+		return superspatial.DefaultBindings, ""
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := superspatial.SaveBindings(path, bindings); err != nil {
+			log.Printf("Unable to save controls to %s: %v", path, err)
+		}
+	}
+	return bindings, path
+}
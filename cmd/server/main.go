@@ -14,6 +14,7 @@ func main() {
 	host := flag.String("host", "127.0.0.1", "receptionist host address")
 	port := flag.Int("port", 7777, "receptionist port")
 	workerID := flag.String("worker", "", "worker ID")
+	topology := flag.String("topology", "", "world edges: bounded or wrap (defaults to the WORLD_TOPOLOGY worker flag)")
 	flag.Parse()
 
 	opts := engo.RunOptions{
@@ -21,7 +22,7 @@ func main() {
 		HeadlessMode: true,
 		FPSLimit:     30,
 	}
-	ss := superspatial.ServerScene{WorkerTypeName: "Server", Host: *host, Port: *port, WorkerID: *workerID}
+	ss := superspatial.ServerScene{WorkerTypeName: "Server", Host: *host, Port: *port, WorkerID: *workerID, Topology: *topology}
 
 	engo.Run(opts, &ss)
 }
@@ -0,0 +1,370 @@
+package superspatial
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ScottBrooks/sos"
+)
+
+// How long we wait on a command's response when its type doesn't say.
+const defaultCommandTimeout = 5 * time.Second
+
+// We give the runtime this much longer than the timeout to tell us it timed out, before giving up on it ourselves.
+const commandTimeoutGrace = time.Second
+
+var ErrCommandTimeout = errors.New("Command timed out")
+
+// Commands are sent with the runtime's commands when the SpatialSystem can send them.  When it can't, the caller
+// keeps its requests in a queue component on the entity, and whoever handles them acks each one in an acks component
+// with its response.  A request stays queued until it's acked or times out, so nothing is lost when several are sent
+// before the first is answered.  Requests and responses carry a Seq, so the caller can tell which request a response
+// is for.
+
+// CommandKey is a command's component and its index in that component's schema, counting from 1.
+type CommandKey struct {
+	CID   sos.ComponentID
+	Index uint32
+}
+
+// CommandRequest is a command's request type.
+type CommandRequest interface {
+	RequestSeq() int64
+}
+
+// CommandResponse is a command's response type, carrying the Seq of the request it answers.
+type CommandResponse interface {
+	ResponseSeq() int64
+}
+
+// commandQueue is a queue component, with the requests still waiting on an ack.
+type commandQueue interface {
+	QueuedRequests() []CommandRequest
+}
+
+// commandAcks is an acks component, with a response for each request in the queue that's been answered.
+type commandAcks interface {
+	AckedResponses() []CommandResponse
+}
+
+// commandType is how to send a command without the runtime's commands.
+type commandType struct {
+	Timeout time.Duration
+	Queue   sos.ComponentID
+	Acks    sos.ComponentID
+	// NewQueue and NewAcks build the queue and acks components.
+	NewQueue func([]CommandRequest) interface{}
+	NewAcks  func([]CommandResponse) interface{}
+}
+
+// commandTypes are all the commands in the schema.
+var commandTypes = map[CommandKey]commandType{
+	sendMessageCommand: {
+		Timeout:  chatTimeout,
+		Queue:    cidSendMessageQueue,
+		Acks:     cidSendMessageAcks,
+		NewQueue: newSendMessageQueue,
+		NewAcks:  newSendMessageAcks,
+	},
+	respawnCommand: {
+		Queue:    cidRespawnQueue,
+		Acks:     cidRespawnAcks,
+		NewQueue: newRespawnQueue,
+		NewAcks:  newRespawnAcks,
+	},
+}
+
+// commandUsing finds the command with the queue or acks component CID.
+func commandUsing(CID sos.ComponentID) (CommandKey, commandType, bool) {
+	for key, ct := range commandTypes {
+		if ct.Queue == CID || ct.Acks == CID {
+			return key, ct, true
+		}
+	}
+	return CommandKey{}, commandType{}, false
+}
+
+// CommandError is a command that didn't succeed, with the status code and message from the runtime.
+type CommandError struct {
+	StatusCode int
+	Message    string
+}
+
+func (ce CommandError) Error() string {
+	return fmt.Sprintf("Command failed(%d): %s", ce.StatusCode, ce.Message)
+}
+
+// CommandResult is what came back from a command we sent.
+type CommandResult struct {
+	Response CommandResponse
+	Err      error
+}
+
+// CommandHandler answers a request sent to entity ID, the typed Handle functions wrap the handlers they're given in
+// one.
+type CommandHandler func(ID sos.EntityID, req CommandRequest) CommandResponse
+
+type pendingCommand struct {
+	ID       sos.EntityID
+	Key      CommandKey
+	Deadline time.Time
+	Done     func(CommandResult)
+	// Queued is a request waiting in a queue component, rather than sent with the runtime.
+	Queued bool
+}
+
+// commandQueueKey is a queue component on an entity.
+type commandQueueKey struct {
+	ID  sos.EntityID
+	Key CommandKey
+}
+
+// Commands holds the handlers a scene answers commands with, and the commands it's waiting on responses for, by
+// their request ID.  Queued requests use their Seq as their request ID.  Everything runs on the game loop: handlers
+// when the request comes in, and callbacks when the response does or the command times out.
+type Commands struct {
+	handlers map[CommandKey]CommandHandler
+	pending  map[sos.RequestID]pendingCommand
+	seq      int64
+
+	// queued are the requests we're waiting on in each queue we write to, and answered the responses we've given to
+	// each queue we handle.
+	queued   map[commandQueueKey][]CommandRequest
+	answered map[commandQueueKey]map[int64]CommandResponse
+}
+
+// Handle answers the command key with h, replacing any handler already there.
+func (c *Commands) Handle(key CommandKey, h CommandHandler) {
+	if c.handlers == nil {
+		c.handlers = map[CommandKey]CommandHandler{}
+	}
+	c.handlers[key] = h
+}
+
+// Unhandle stops answering the command key.
+func (c *Commands) Unhandle(key CommandKey) {
+	delete(c.handlers, key)
+}
+
+// nextSeq is the Seq for the next request we send.
+func (c *Commands) nextSeq() int64 {
+	c.seq++
+	return c.seq
+}
+
+// request runs the handler for a request sent to entity ID, handled is false if there isn't one.
+func (c *Commands) request(ID sos.EntityID, key CommandKey, req CommandRequest) (resp CommandResponse, handled bool) {
+	h, ok := c.handlers[key]
+	if !ok {
+		return nil, false
+	}
+	return h(ID, req), true
+}
+
+func (c *Commands) track(RID sos.RequestID, p pendingCommand) {
+	if c.pending == nil {
+		c.pending = map[sos.RequestID]pendingCommand{}
+	}
+	c.pending[RID] = p
+}
+
+// respond finishes the command sent as RID, reporting false if we weren't waiting on it.
+func (c *Commands) respond(RID sos.RequestID, r CommandResult) bool {
+	p, ok := c.pending[RID]
+	if !ok {
+		return false
+	}
+	delete(c.pending, RID)
+	p.Done(r)
+	return true
+}
+
+// enqueue adds req to the queue for key on entity ID, returning everything now in it.
+func (c *Commands) enqueue(ID sos.EntityID, key CommandKey, req CommandRequest) []CommandRequest {
+	if c.queued == nil {
+		c.queued = map[commandQueueKey][]CommandRequest{}
+	}
+	qk := commandQueueKey{ID, key}
+	c.queued[qk] = append(c.queued[qk], req)
+	return c.queued[qk]
+}
+
+// dequeue drops the request seq from the queue for key on entity ID, returning what's left.
+func (c *Commands) dequeue(ID sos.EntityID, key CommandKey, seq int64) []CommandRequest {
+	qk := commandQueueKey{ID, key}
+	reqs := []CommandRequest{}
+	for _, req := range c.queued[qk] {
+		if req.RequestSeq() != seq {
+			reqs = append(reqs, req)
+		}
+	}
+	if len(reqs) == 0 {
+		delete(c.queued, qk)
+	} else {
+		c.queued[qk] = reqs
+	}
+	return reqs
+}
+
+// answer handles whatever's new in the queue for key on entity ID, returning the acks for everything in it.  changed
+// is false if there's nothing new to ack, or the command isn't ours to answer.
+func (c *Commands) answer(ID sos.EntityID, key CommandKey, reqs []CommandRequest) (acks []CommandResponse, changed bool) {
+	if _, ok := c.handlers[key]; !ok {
+		return nil, false
+	}
+	if c.answered == nil {
+		c.answered = map[commandQueueKey]map[int64]CommandResponse{}
+	}
+	qk := commandQueueKey{ID, key}
+	last := c.answered[qk]
+	answered := map[int64]CommandResponse{}
+	for _, req := range reqs {
+		resp, ok := last[req.RequestSeq()]
+		if !ok {
+			resp, _ = c.request(ID, key, req)
+			changed = true
+		}
+		answered[req.RequestSeq()] = resp
+		acks = append(acks, resp)
+	}
+	// Anything the caller has stopped waiting on drops out of the acks.
+	if len(answered) != len(last) {
+		changed = true
+	}
+	c.answered[qk] = answered
+	return acks, changed
+}
+
+// acked finishes the commands acked in the acks for key on entity ID, returning what's left in our queue.  changed is
+// false if none of them were ours.
+func (c *Commands) acked(ID sos.EntityID, key CommandKey, acks []CommandResponse) (reqs []CommandRequest, changed bool) {
+	reqs = c.queued[commandQueueKey{ID, key}]
+	for _, resp := range acks {
+		RID := sos.RequestID(resp.ResponseSeq())
+		if p, ok := c.pending[RID]; !ok || !p.Queued || p.ID != ID || p.Key != key {
+			continue
+		}
+		reqs = c.dequeue(ID, key, resp.ResponseSeq())
+		c.respond(RID, CommandResult{Response: resp})
+		changed = true
+	}
+	return reqs, changed
+}
+
+// expire gives up on anything still waiting past its deadline, returning the queues it dropped requests from.
+func (c *Commands) expire(now time.Time) []commandQueueKey {
+	var dropped []commandQueueKey
+	for RID, p := range c.pending {
+		if !now.After(p.Deadline) {
+			continue
+		}
+		if p.Queued {
+			c.dequeue(p.ID, p.Key, int64(RID))
+			dropped = append(dropped, commandQueueKey{p.ID, p.Key})
+		}
+		c.respond(RID, CommandResult{Err: ErrCommandTimeout})
+	}
+	return dropped
+}
+
+// sendCommand sends req to the command key on entity ID, done is called with the response once it's back.  req's Seq
+// has to come from nextSeq.
+func (ss *ServerScene) sendCommand(ID sos.EntityID, key CommandKey, req CommandRequest, done func(CommandResult)) {
+	ct, ok := commandTypes[key]
+	if !ok {
+		done(CommandResult{Err: fmt.Errorf("Unknown command %d on component %d", key.Index, key.CID)})
+		return
+	}
+	timeout := ct.Timeout
+	if timeout == 0 {
+		timeout = defaultCommandTimeout
+	}
+
+	if sender, ok := interface{}(ss.spatial).(commandSender); ok {
+		RID := sender.SendCommandRequest(ID, key.CID, key.Index, req, uint32(timeout/time.Millisecond))
+		ss.Commands.track(RID, pendingCommand{ID: ID, Key: key, Deadline: time.Now().Add(timeout + commandTimeoutGrace), Done: done})
+		return
+	}
+	ss.spatial.UpdateComponent(ID, ct.Queue, ct.NewQueue(ss.Commands.enqueue(ID, key, req)))
+	ss.Commands.track(sos.RequestID(req.RequestSeq()), pendingCommand{ID: ID, Key: key, Deadline: time.Now().Add(timeout), Done: done, Queued: true})
+}
+
+// sendCommandFuture is sendCommand for callers that would rather wait on a channel.  It's only ready once the game
+// loop has run the response in, so don't wait on it from there.
+func (ss *ServerScene) sendCommandFuture(ID sos.EntityID, key CommandKey, req CommandRequest) <-chan CommandResult {
+	result := make(chan CommandResult, 1)
+	ss.sendCommand(ID, key, req, func(r CommandResult) { result <- r })
+	return result
+}
+
+// expireCommands gives up on commands that have been waiting too long.
+func (ss *ServerScene) expireCommands(now time.Time) {
+	for _, qk := range ss.Commands.expire(now) {
+		ct := commandTypes[qk.Key]
+		ss.spatial.UpdateComponent(qk.ID, ct.Queue, ct.NewQueue(ss.Commands.queued[qk]))
+	}
+}
+
+// commandUpdate answers op if it's a queue we handle, or finishes the commands acked in it.  It reports if op was
+// part of a command at all.
+func (ss *ServerScene) commandUpdate(op sos.ComponentUpdateOp) bool {
+	key, ct, ok := commandUsing(op.CID)
+	if !ok {
+		return false
+	}
+	switch c := op.Component.(type) {
+	case commandQueue:
+		if acks, changed := ss.Commands.answer(op.ID, key, c.QueuedRequests()); changed {
+			ss.spatial.UpdateComponent(op.ID, ct.Acks, ct.NewAcks(acks))
+		}
+	case commandAcks:
+		if reqs, changed := ss.Commands.acked(op.ID, key, c.AckedResponses()); changed {
+			ss.spatial.UpdateComponent(op.ID, ct.Queue, ct.NewQueue(reqs))
+		}
+	}
+	return true
+}
+
+func (ss *ServerScene) OnCommandRequest(op sos.CommandRequestOp) {
+	r, ok := interface{}(op).(commandRequestOp)
+	sender, canSend := interface{}(ss.spatial).(commandSender)
+	if !ok || !canSend {
+		log.Printf("Unable to answer command: %+v", op)
+		return
+	}
+
+	req, ok := r.Request().(CommandRequest)
+	if !ok {
+		sender.SendCommandFailure(r.RequestID(), "Unknown command")
+		return
+	}
+	key := CommandKey{r.ComponentID(), r.CommandIndex()}
+	resp, handled := ss.Commands.request(r.EntityID(), key, req)
+	if !handled {
+		log.Printf("No handler for command: %+v", key)
+		sender.SendCommandFailure(r.RequestID(), "Unhandled command")
+		return
+	}
+	sender.SendCommandResponse(r.RequestID(), key.CID, key.Index, resp)
+}
+
+func (ss *ServerScene) OnCommandResponse(op sos.CommandResponseOp) {
+	r, ok := interface{}(op).(commandResponseOp)
+	if !ok {
+		log.Printf("Unable to read command response: %+v", op)
+		return
+	}
+
+	result := CommandResult{}
+	if r.StatusCode() != statusSuccess {
+		result.Err = CommandError{StatusCode: r.StatusCode(), Message: r.Message()}
+	} else if resp, ok := r.Response().(CommandResponse); ok {
+		result.Response = resp
+	} else {
+		result.Err = fmt.Errorf("Unexpected response %T", r.Response())
+	}
+	if !ss.Commands.respond(r.RequestID(), result) {
+		log.Printf("Response to a command we aren't waiting on: %+v", op)
+	}
+}
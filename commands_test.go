@@ -0,0 +1,86 @@
+package superspatial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ScottBrooks/sos"
+)
+
+func TestCommandsAnswer(t *testing.T) {
+	var c Commands
+	queue := SendMessageQueue{Requests: []SendMessageRequest{{Seq: 1, Text: "hi"}, {Seq: 2, Text: "there"}}}
+	if _, changed := c.answer(1, sendMessageCommand, queue.QueuedRequests()); changed {
+		t.Fatal("answered a command with no handler")
+	}
+
+	var heard []string
+	c.HandleSendMessage(func(ID sos.EntityID, req SendMessageRequest) SendMessageResponse {
+		heard = append(heard, req.Text)
+		return SendMessageResponse{Accepted: req.Text != "there"}
+	})
+	acks, changed := c.answer(1, sendMessageCommand, queue.QueuedRequests())
+	if !changed {
+		t.Fatal("nothing to ack")
+	}
+	want := SendMessageAcks{Responses: []SendMessageResponse{{Seq: 1, Accepted: true}, {Seq: 2}}}
+	if got := newSendMessageAcks(acks).(SendMessageAcks); len(got.Responses) != 2 || got.Responses[0] != want.Responses[0] || got.Responses[1] != want.Responses[1] {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// The same queue again is nothing new, and each request is only handled once.
+	if _, changed := c.answer(1, sendMessageCommand, queue.QueuedRequests()); changed {
+		t.Error("acked the same queue twice")
+	}
+	queue.Requests = queue.Requests[1:]
+	if acks, changed := c.answer(1, sendMessageCommand, queue.QueuedRequests()); !changed || len(acks) != 1 {
+		t.Errorf("dropping an acked request: got %+v, %v", acks, changed)
+	}
+	if len(heard) != 2 {
+		t.Errorf("handled %v, want each message once", heard)
+	}
+}
+
+func TestCommandsRespond(t *testing.T) {
+	var c Commands
+	var results []CommandResult
+	done := func(r CommandResult) { results = append(results, r) }
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		req := SendMessageRequest{Seq: c.nextSeq()}
+		c.enqueue(10, sendMessageCommand, req)
+		c.track(sos.RequestID(req.Seq), pendingCommand{ID: 10, Key: sendMessageCommand, Deadline: now.Add(time.Second), Done: done, Queued: true})
+	}
+	c.track(7, pendingCommand{ID: 10, Key: respawnCommand, Deadline: now.Add(time.Minute), Done: done})
+
+	if _, changed := c.acked(11, sendMessageCommand, []CommandResponse{SendMessageResponse{Seq: 1}}); changed {
+		t.Error("acked a command on another entity")
+	}
+	reqs, changed := c.acked(10, sendMessageCommand, []CommandResponse{SendMessageResponse{Seq: 1, Accepted: true}})
+	if !changed || len(reqs) != 1 || reqs[0].RequestSeq() != 2 {
+		t.Errorf("acked: got %+v, %v", reqs, changed)
+	}
+	c.respond(7, CommandResult{Err: CommandError{StatusCode: 5, Message: "nope"}})
+
+	dropped := c.expire(now.Add(2 * time.Second))
+	if len(dropped) != 1 || len(c.queued) != 0 {
+		t.Errorf("expired: dropped %+v, still queued %+v", dropped, c.queued)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if resp, ok := results[0].Response.(SendMessageResponse); !ok || !resp.Accepted || results[0].Err != nil {
+		t.Errorf("success: got %+v", results[0])
+	}
+	if _, ok := results[1].Err.(CommandError); !ok {
+		t.Errorf("failure: got %+v", results[1])
+	}
+	if results[2].Err != ErrCommandTimeout {
+		t.Errorf("expired: got %+v", results[2])
+	}
+	if c.respond(2, CommandResult{}) {
+		t.Error("responded to a command that had already expired")
+	}
+}
@@ -5,6 +5,7 @@ const cidMetadata = 53
 const cidPosition = 54
 const cidInterest = 58
 const cidWorker = 60
+const cidPlayerClient = 61
 
 const cidShip = 1000
 const cidGame = 1002
@@ -12,3 +13,20 @@ const cidPlayerInput = 1003
 const cidBalancer = 1004
 const cidWorkerBalancer = 1005
 const cidEffect = 1006
+const cidScore = 1007
+const cidLeaderboard = 1008
+const cidPlayer = 1009
+const cidShipAppearance = 1010
+const cidTeam = 1011
+const cidMatch = 1012
+const cidNotification = 1013
+const cidChat = 1014
+const cidGlobalChat = 1015
+const cidServerWorker = 1017
+const cidBalancerStandby = 1018
+const cidObstacle = 1019
+const cidSession = 1020
+const cidSendMessageQueue = 1021
+const cidSendMessageAcks = 1022
+const cidRespawnQueue = 1023
+const cidRespawnAcks = 1024
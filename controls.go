@@ -0,0 +1,299 @@
+package superspatial
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EngoEngine/engo"
+)
+
+// GamepadBindings map gamepad sticks, triggers and buttons onto the ship's controls.
+type GamepadBindings struct {
+	Enabled bool `json:"enabled"`
+	// Thrust and Turn are axis names, see gamepadAxes.
+	Thrust string `json:"thrust"`
+	Turn   string `json:"turn"`
+	// Attack is a button name, see gamepadButtons.
+	Attack string `json:"attack"`
+	// Axis values closer to zero than this are ignored, so worn sticks don't drift.
+	Deadzone float32 `json:"deadzone"`
+}
+
+// Bindings are the controls a player has set up, loaded from controls.json in their config directory.
+type Bindings struct {
+	// Keys are the key names bound to each control(Up, Down, Left, Right, Attack, Enter, Scoreboard, Pause), any of
+	// them will do.  The menus use Up, Down and Enter too.
+	Keys    map[string][]string `json:"keys"`
+	Gamepad GamepadBindings     `json:"gamepad"`
+}
+
+// DefaultBindings stay off the letter keys, so typing a name in the hangar doesn't fly the ship or move the menus.
+var DefaultBindings = Bindings{
+	Keys: map[string][]string{
+		"Up":         {"ArrowUp"},
+		"Down":       {"ArrowDown"},
+		"Left":       {"ArrowLeft"},
+		"Right":      {"ArrowRight"},
+		"Attack":     {"Space"},
+		"Enter":      {"Enter"},
+		"Scoreboard": {"Tab"},
+		"Pause":      {"Escape"},
+	},
+	Gamepad: GamepadBindings{
+		Enabled:  true,
+		Thrust:   "-LeftY",
+		Turn:     "LeftX",
+		Attack:   "A",
+		Deadzone: 0.2,
+	},
+}
+
+var keyNames = map[string]engo.Key{
+	"ArrowUp":      engo.KeyArrowUp,
+	"ArrowDown":    engo.KeyArrowDown,
+	"ArrowLeft":    engo.KeyArrowLeft,
+	"ArrowRight":   engo.KeyArrowRight,
+	"Enter":        engo.KeyEnter,
+	"Space":        engo.KeySpace,
+	"Escape":       engo.KeyEscape,
+	"Tab":          engo.KeyTab,
+	"Backspace":    engo.KeyBackspace,
+	"LeftShift":    engo.KeyLeftShift,
+	"RightShift":   engo.KeyRightShift,
+	"LeftControl":  engo.KeyLeftControl,
+	"RightControl": engo.KeyRightControl,
+}
+
+func init() {
+	for i, k := range []engo.Key{engo.KeyZero, engo.KeyOne, engo.KeyTwo, engo.KeyThree, engo.KeyFour, engo.KeyFive, engo.KeySix, engo.KeySeven, engo.KeyEight, engo.KeyNine} {
+		keyNames[fmt.Sprint(i)] = k
+	}
+	letters := []engo.Key{
+		engo.KeyA, engo.KeyB, engo.KeyC, engo.KeyD, engo.KeyE, engo.KeyF, engo.KeyG, engo.KeyH, engo.KeyI, engo.KeyJ,
+		engo.KeyK, engo.KeyL, engo.KeyM, engo.KeyN, engo.KeyO, engo.KeyP, engo.KeyQ, engo.KeyR, engo.KeyS, engo.KeyT,
+		engo.KeyU, engo.KeyV, engo.KeyW, engo.KeyX, engo.KeyY, engo.KeyZ,
+	}
+	for i, k := range letters {
+		keyNames[string(rune('A'+i))] = k
+	}
+}
+
+var gamepadAxes = map[string]func(*engo.Gamepad) float32{
+	"LeftX":        func(g *engo.Gamepad) float32 { return g.LeftX.Value() },
+	"LeftY":        func(g *engo.Gamepad) float32 { return g.LeftY.Value() },
+	"RightX":       func(g *engo.Gamepad) float32 { return g.RightX.Value() },
+	"RightY":       func(g *engo.Gamepad) float32 { return g.RightY.Value() },
+	"LeftTrigger":  func(g *engo.Gamepad) float32 { return g.LeftTrigger.Value() },
+	"RightTrigger": func(g *engo.Gamepad) float32 { return g.RightTrigger.Value() },
+}
+
+var gamepadButtons = map[string]func(*engo.Gamepad) engo.GamepadButton{
+	"A":           func(g *engo.Gamepad) engo.GamepadButton { return g.A },
+	"B":           func(g *engo.Gamepad) engo.GamepadButton { return g.B },
+	"X":           func(g *engo.Gamepad) engo.GamepadButton { return g.X },
+	"Y":           func(g *engo.Gamepad) engo.GamepadButton { return g.Y },
+	"LeftBumper":  func(g *engo.Gamepad) engo.GamepadButton { return g.LeftBumper },
+	"RightBumper": func(g *engo.Gamepad) engo.GamepadButton { return g.RightBumper },
+}
+
+// BindingsPath is where a player's bindings live.
+func BindingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "superspatial", "controls.json"), nil
+}
+
+// LoadBindings reads bindings from path.  A missing file gives the defaults, and any controls the file leaves out keep
+// their default keys.
+func LoadBindings(path string) (Bindings, error) {
+	b := Bindings{Keys: map[string][]string{}, Gamepad: DefaultBindings.Gamepad}
+	for control, keys := range DefaultBindings.Keys {
+		b.Keys[control] = keys
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return b, nil
+	}
+	if err != nil {
+		return b, err
+	}
+
+	var loaded Bindings
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return b, fmt.Errorf("Error parsing %s: %v", path, err)
+	}
+	for control, keys := range loaded.Keys {
+		b.Keys[control] = keys
+	}
+	if loaded.Gamepad != (GamepadBindings{}) {
+		b.Gamepad = loaded.Gamepad
+	}
+	return b, b.Validate()
+}
+
+// SaveBindings writes b to path, so players have a file to edit.
+func SaveBindings(path string, b Bindings) error {
+	data, err := json.MarshalIndent(b, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Validate checks every key, axis and button name is one we know.
+func (b Bindings) Validate() error {
+	for control, keys := range b.Keys {
+		for _, k := range keys {
+			if _, ok := keyNames[k]; !ok {
+				return fmt.Errorf("Unknown key %s for %s", k, control)
+			}
+		}
+	}
+	if !b.Gamepad.Enabled {
+		return nil
+	}
+	for _, axis := range []string{b.Gamepad.Thrust, b.Gamepad.Turn} {
+		if _, ok := gamepadAxes[strings.TrimPrefix(axis, "-")]; !ok {
+			return fmt.Errorf("Unknown gamepad axis: %s", axis)
+		}
+	}
+	if _, ok := gamepadButtons[b.Gamepad.Attack]; !ok {
+		return fmt.Errorf("Unknown gamepad button: %s", b.Gamepad.Attack)
+	}
+	return nil
+}
+
+// Register sets up engo buttons for every control, and the gamepad if it's enabled.
+func (b Bindings) Register() {
+	for control, names := range b.Keys {
+		keys := []engo.Key{}
+		for _, name := range names {
+			if k, ok := keyNames[name]; ok {
+				keys = append(keys, k)
+			}
+		}
+		engo.Input.RegisterButton(control, keys...)
+	}
+	for name, k := range keyNames {
+		engo.Input.RegisterButton(rebindButton(name), k)
+	}
+
+	if b.Gamepad.Enabled {
+		if err := engo.Input.RegisterGamepad("Player"); err != nil {
+			log.Printf("No gamepad: %v", err)
+		}
+	}
+}
+
+// ShipControls are the controls that can be rebound from the settings screen, in the order they're listed.
+var ShipControls = []string{"Up", "Down", "Left", "Right", "Attack"}
+
+// Rebind makes key the only key for control.  The key map is copied first, so the defaults are never changed.
+func (b *Bindings) Rebind(control, key string) error {
+	if _, ok := keyNames[key]; !ok {
+		return fmt.Errorf("Unknown key %s for %s", key, control)
+	}
+	keys := map[string][]string{}
+	for c, k := range b.Keys {
+		keys[c] = k
+	}
+	keys[control] = []string{key}
+	b.Keys = keys
+	return nil
+}
+
+// Label is how control and its keys are shown on the settings screen.
+func (b Bindings) Label(control string) string {
+	return fmt.Sprintf("%s: %s", control, strings.Join(b.Keys[control], ", "))
+}
+
+// rebindButton is the engo button for a single key, so we can tell which key was pressed while rebinding.
+func rebindButton(name string) string {
+	return "Key" + name
+}
+
+// PressedKey is the name of a key that was just pressed, if any.
+func PressedKey() (string, bool) {
+	for name := range keyNames {
+		if engo.Input.Button(rebindButton(name)).JustPressed() {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Read turns the current state of the keyboard and gamepad into ship input.  Throttle and Turn run from -1 to 1,
+// whichever of the keyboard or gamepad is pushed further wins.
+func (b Bindings) Read() PlayerInputComponent {
+	var p PlayerInputComponent
+	p.Throttle = buttonAxis("Down", "Up")
+	p.Turn = buttonAxis("Left", "Right")
+	p.Attack = engo.Input.Button("Attack").Down()
+
+	if gp := engo.Input.Gamepad("Player"); b.Gamepad.Enabled && gp != nil {
+		p.Throttle = strongest(p.Throttle, b.gamepadAxis(gp, b.Gamepad.Thrust))
+		p.Turn = strongest(p.Turn, b.gamepadAxis(gp, b.Gamepad.Turn))
+		p.Attack = p.Attack || gamepadButtons[b.Gamepad.Attack](gp).Down()
+	}
+
+	p.setButtons()
+	return p
+}
+
+// gamepadAxis reads the axis called name, a leading - flips it.
+func (b Bindings) gamepadAxis(gp *engo.Gamepad, name string) float32 {
+	read, ok := gamepadAxes[strings.TrimPrefix(name, "-")]
+	if !ok {
+		return 0
+	}
+	v := read(gp)
+	if strings.HasPrefix(name, "-") {
+		v = -v
+	}
+	return deadzone(v, b.Gamepad.Deadzone)
+}
+
+func buttonAxis(min, max string) float32 {
+	var v float32
+	if engo.Input.Button(min).Down() {
+		v--
+	}
+	if engo.Input.Button(max).Down() {
+		v++
+	}
+	return v
+}
+
+// deadzone zeroes v inside the deadzone, and rescales the rest so it still runs smoothly from 0 to 1.
+func deadzone(v float32, dz float32) float32 {
+	if v < dz && v > -dz {
+		return 0
+	}
+	if v > 1 {
+		v = 1
+	}
+	if v < -1 {
+		v = -1
+	}
+	if v > 0 {
+		return (v - dz) / (1 - dz)
+	}
+	return (v + dz) / (1 - dz)
+}
+
+func strongest(a, b float32) float32 {
+	if b*b > a*a {
+		return b
+	}
+	return a
+}
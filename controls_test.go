@@ -0,0 +1,96 @@
+package superspatial
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadBindings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "controls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "controls.json")
+
+	b, err := LoadBindings(path)
+	if err != nil {
+		t.Fatalf("expected defaults for a missing file, got %v", err)
+	}
+	if !reflect.DeepEqual(b, DefaultBindings) {
+		t.Errorf("got %+v, want defaults", b)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"keys": {"Attack": ["Space", "LeftControl"], "Up": ["I"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b, err = LoadBindings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b.Keys["Attack"], []string{"Space", "LeftControl"}) || !reflect.DeepEqual(b.Keys["Up"], []string{"I"}) {
+		t.Errorf("expected Attack and Up to be rebound, got %+v", b.Keys)
+	}
+	if !reflect.DeepEqual(b.Keys["Left"], DefaultBindings.Keys["Left"]) || b.Gamepad != DefaultBindings.Gamepad {
+		t.Errorf("expected everything else to keep its defaults, got %+v", b)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"keys": {"Up": ["Banana"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBindings(path); err == nil {
+		t.Errorf("expected an error for an unknown key")
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"gamepad": {"enabled": true, "thrust": "LeftZ", "turn": "LeftX", "attack": "A"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBindings(path); err == nil {
+		t.Errorf("expected an error for an unknown axis")
+	}
+
+	if err := SaveBindings(path, DefaultBindings); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := LoadBindings(path); err != nil || !reflect.DeepEqual(b, DefaultBindings) {
+		t.Errorf("expected saved defaults to load back, got %+v %v", b, err)
+	}
+}
+
+func TestDeadzone(t *testing.T) {
+	var tests = []struct {
+		v, want float32
+	}{
+		{0, 0},
+		{0.1, 0},
+		{-0.19, 0},
+		{0.6, 0.5},
+		{-0.6, -0.5},
+		{1, 1},
+		{-1.2, -1},
+	}
+	for _, tt := range tests {
+		if got := deadzone(tt.v, 0.2); got < tt.want-0.001 || got > tt.want+0.001 {
+			t.Errorf("deadzone(%f) = %f, want %f", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	b := DefaultBindings
+	if err := b.Rebind("Attack", "LeftControl"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b.Keys["Attack"], []string{"LeftControl"}) {
+		t.Errorf("got %v, want Attack on LeftControl", b.Keys["Attack"])
+	}
+	if !reflect.DeepEqual(DefaultBindings.Keys["Attack"], []string{"Space"}) {
+		t.Errorf("rebinding changed the defaults to %v", DefaultBindings.Keys["Attack"])
+	}
+	if err := b.Rebind("Up", "Banana"); err == nil {
+		t.Errorf("expected an error for an unknown key")
+	}
+}
@@ -21,6 +21,7 @@ func (ss *ServerScene) NewEffect(pos mgl32.Vec3, effect int, expiry int) {
 		{[]string{"position"}},
 	}
 	readAcl := WorkerRequirementSet{AttributeSet: readAttrSet}
+	// We don't know which balancer leads, it claims these when the entity turns up.
 	writeAcl := map[uint32]WorkerRequirementSet{
 		cidEffect:         WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
 		cidPosition:       WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
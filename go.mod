@@ -12,4 +12,4 @@ require (
 
 replace github.com/ScottBrooks/sos => ../sos
 
-go 1.12
+go 1.13
@@ -9,28 +9,50 @@ import (
 type HudElement struct {
 	*ecs.BasicEntity
 	*common.SpaceComponent
+	Render *common.RenderComponent
 	Offset engo.Point
+
+	baseScale engo.Point
 }
 
 type HudSystem struct {
 	Pos    *engo.Point
 	Camera *common.CameraSystem
+	// Scale grows or shrinks the whole hud around the middle of the screen, 0 is the same as 1.
+	Scale float32
 
 	Entities []HudElement
 }
 
-func (hs *HudSystem) Add(ent *ecs.BasicEntity, sc *common.SpaceComponent, offset engo.Point) {
-	hs.Entities = append(hs.Entities, HudElement{ent, sc, offset})
+func (hs *HudSystem) Add(ent *ecs.BasicEntity, rc *common.RenderComponent, sc *common.SpaceComponent, offset engo.Point) {
+	base := rc.Scale
+	if base == (engo.Point{}) {
+		base = engo.Point{X: 1, Y: 1}
+	}
+	hs.Entities = append(hs.Entities, HudElement{ent, sc, rc, offset, base})
 }
 func (hs *HudSystem) Remove(ecs.BasicEntity) {}
 func (hs *HudSystem) Update(dt float32) {
 	if hs.Camera == nil {
 		return
 	}
-
 	for _, e := range hs.Entities {
-		offset := e.Offset
-		offset.Add(*hs.Pos).Add(engo.Point{X: hs.Camera.X(), Y: hs.Camera.Y()}).Add(e.Offset).Subtract(common.CameraBounds.Min)
-		e.SpaceComponent.Position = offset
+		e.SpaceComponent.Position = hs.ScreenPos(e.Offset)
+		e.Render.Scale = e.baseScale
+		e.Render.Scale.MultiplyScalar(hs.scale())
+	}
+}
+
+// ScreenPos is where something offset from the middle of the screen ends up in the world.
+func (hs *HudSystem) ScreenPos(offset engo.Point) engo.Point {
+	offset.MultiplyScalar(hs.scale())
+	offset.Add(*hs.Pos).Add(engo.Point{X: hs.Camera.X(), Y: hs.Camera.Y()}).Subtract(common.CameraBounds.Min)
+	return offset
+}
+
+func (hs *HudSystem) scale() float32 {
+	if hs.Scale == 0 {
+		return 1
 	}
+	return hs.Scale
 }
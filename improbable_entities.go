@@ -28,6 +28,16 @@ type ImprobableWorker struct {
 	//Connection
 }
 
+type ImprobablePlayerIdentity struct {
+	PlayerIdentifier string
+	Provider         string
+	Metadata         []byte
+}
+
+type ImprobablePlayerClient struct {
+	PlayerIdentity ImprobablePlayerIdentity
+}
+
 type ImprobableMetadata struct {
 	Name string
 }
@@ -53,6 +63,14 @@ type ShipComponent struct {
 	Vel    mgl32.Vec3
 	Angle  float32
 	Radius float32
+	// ProtectedUntil is when spawn protection runs out, in unix milliseconds.
+	ProtectedUntil int64
+	// Spin is how fast the ship is turning, in degrees a second.
+	Spin float32
+	// Health runs down from maxShipHealth as the ship is hit, it's destroyed when there's none left.
+	Health float32
+	// AttackReadyAt is when the ship can hurt anyone again after its last hit, in unix milliseconds.
+	AttackReadyAt int64
 }
 
 type PlayerInputComponent struct {
@@ -61,6 +79,9 @@ type PlayerInputComponent struct {
 	Forward bool
 	Back    bool
 	Attack  bool
+	// Throttle and Turn run from -1 to 1, for analog sticks.
+	Throttle float32
+	Turn     float32
 }
 
 type EffectComponent struct {
@@ -68,3 +89,35 @@ type EffectComponent struct {
 	Expiry int32
 	Pos    mgl32.Vec3
 }
+
+type ScoreComponent struct {
+	Kills  int32
+	Deaths int32
+	Streak int32
+}
+
+type LeaderboardEntry struct {
+	Name   string
+	Kills  int32
+	Deaths int32
+	Streak int32
+}
+
+type TeamScore struct {
+	Team   int32
+	Kills  int32
+	Deaths int32
+}
+
+type LeaderboardComponent struct {
+	Entries []LeaderboardEntry
+	Teams   []TeamScore
+}
+
+type PlayerComponent struct {
+	PlayerID       string
+	Name           string
+	Rating         float32
+	LifetimeKills  int32
+	LifetimeDeaths int32
+}
@@ -0,0 +1,88 @@
+package superspatial
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+const (
+	// How many notifications fit in the feed, older ones get pushed out.
+	killFeedRows = 5
+	killFeedLife = 5 * time.Second
+	// Notifications fade out over the end of their life.
+	killFeedFade = time.Second
+)
+
+type killFeedEntry struct {
+	Text string
+	At   time.Time
+}
+
+// KillFeedSystem lists recent kills, joins, leaves and streaks in the top right corner.
+type KillFeedSystem struct {
+	Font *common.Font
+
+	rows    [killFeedRows]Text
+	entries []killFeedEntry
+	dirty   bool
+}
+
+// Setup builds the feed, adding the rows to rs and pinning them under offset with hs.
+func (kfs *KillFeedSystem) Setup(rs *common.RenderSystem, hs *HudSystem, offset engo.Point) {
+	for i := range kfs.rows {
+		row := &kfs.rows[i]
+		*row = Text{BasicEntity: ecs.NewBasic()}
+		row.RenderComponent.Drawable = common.Text{Font: kfs.Font, Text: " "}
+		row.RenderComponent.SetZIndex(100)
+		row.RenderComponent.Hidden = true
+		rs.Add(&row.BasicEntity, &row.RenderComponent, &row.SpaceComponent)
+		hs.Add(&row.BasicEntity, &row.RenderComponent, &row.SpaceComponent, engo.Point{X: offset.X, Y: offset.Y + float32(i*28)})
+	}
+}
+
+// Push adds text to the top of the feed.
+func (kfs *KillFeedSystem) Push(text string) {
+	kfs.entries = append([]killFeedEntry{{Text: text, At: time.Now()}}, kfs.entries...)
+	if len(kfs.entries) > killFeedRows {
+		kfs.entries = kfs.entries[:killFeedRows]
+	}
+	kfs.dirty = true
+}
+
+func (*KillFeedSystem) Remove(ecs.BasicEntity) {}
+func (kfs *KillFeedSystem) Update(dt float32) {
+	now := time.Now()
+	for len(kfs.entries) > 0 && now.Sub(kfs.entries[len(kfs.entries)-1].At) > killFeedLife {
+		kfs.entries = kfs.entries[:len(kfs.entries)-1]
+		kfs.dirty = true
+	}
+
+	for i := range kfs.rows {
+		row := &kfs.rows[i]
+		row.RenderComponent.Hidden = i >= len(kfs.entries)
+		if i >= len(kfs.entries) {
+			continue
+		}
+		if kfs.dirty {
+			row.RenderComponent.Drawable = common.Text{Font: kfs.Font, Text: kfs.entries[i].Text}
+		}
+		row.RenderComponent.Color = color.NRGBA{255, 255, 255, feedAlpha(now.Sub(kfs.entries[i].At))}
+	}
+	kfs.dirty = false
+}
+
+// feedAlpha is how opaque an entry age old is.
+func feedAlpha(age time.Duration) uint8 {
+	left := killFeedLife - age
+	if left <= 0 {
+		return 0
+	}
+	if left >= killFeedFade {
+		return 255
+	}
+	return uint8(255 * float64(left) / float64(killFeedFade))
+}
@@ -0,0 +1,76 @@
+package superspatial
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/ScottBrooks/sos"
+)
+
+const defaultLeaderboardSize = 10
+
+// buildLeaderboard ranks every client's ship by kills, then fewest deaths, and keeps the top n.
+func buildLeaderboard(entities map[sos.EntityID]*balancedEntity, n int) []LeaderboardEntry {
+	entries := []LeaderboardEntry{}
+	for _, e := range entities {
+		if e.Client == "" {
+			continue
+		}
+		name := e.Player.Name
+		if name == "" {
+			name = e.Client
+		}
+		entries = append(entries, LeaderboardEntry{Name: name, Kills: e.Score.Kills, Deaths: e.Score.Deaths, Streak: e.Score.Streak})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Kills != b.Kills {
+			return a.Kills > b.Kills
+		}
+		if a.Deaths != b.Deaths {
+			return a.Deaths < b.Deaths
+		}
+		return a.Name < b.Name
+	})
+
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// buildTeamScores totals up the kills and deaths of every team in mode.
+func buildTeamScores(entities map[sos.EntityID]*balancedEntity, mode GameMode) []TeamScore {
+	scores := []TeamScore{}
+	for i := 0; i < mode.Teams; i++ {
+		scores = append(scores, TeamScore{Team: int32(i + 1)})
+	}
+	for _, e := range entities {
+		t := int(e.Team.Team)
+		if e.Client == "" || t < 1 || t > len(scores) {
+			continue
+		}
+		scores[t-1].Kills += e.Score.Kills
+		scores[t-1].Deaths += e.Score.Deaths
+	}
+	return scores
+}
+
+// updateLeaderboard rebuilds the leaderboard, and pushes it out if it changed and we own it.
+func (bs *BalancerScene) updateLeaderboard() {
+	if bs.LeaderboardID == 0 {
+		return
+	}
+	size := bs.LeaderboardSize
+	if size == 0 {
+		size = defaultLeaderboardSize
+	}
+
+	lb := LeaderboardComponent{Entries: buildLeaderboard(bs.Entities, size), Teams: buildTeamScores(bs.Entities, bs.Mode)}
+	if reflect.DeepEqual(lb, bs.Leaderboard) {
+		return
+	}
+	bs.Leaderboard = lb
+	bs.spatial.UpdateComponent(bs.LeaderboardID, cidLeaderboard, bs.Leaderboard)
+}
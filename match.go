@@ -0,0 +1,200 @@
+package superspatial
+
+import (
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/ScottBrooks/sos"
+)
+
+// Match states, a match warms up, runs until someone hits the score limit or time runs out, then shows the result
+// before warming up again.
+const (
+	MatchWarmup int32 = iota
+	MatchRunning
+	MatchEnded
+)
+
+type MatchComponent struct {
+	State int32
+	// Round goes up every time a match starts, servers reset scores when they see it change.
+	Round int32
+	// StateEndsAt is when we move on to the next state, in unix milliseconds.
+	StateEndsAt int64
+	ScoreLimit  int32
+	WinnerID    string
+	WinnerName  string
+	WinningTeam int32
+}
+
+// Remaining is how long is left in the current state.
+func (m MatchComponent) Remaining(now time.Time) time.Duration {
+	d := time.Duration(m.StateEndsAt-unixMillis(now)) * time.Millisecond
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Won reports if the player playerID on team won the match.
+func (m MatchComponent) Won(playerID string, team TeamComponent) bool {
+	if m.WinningTeam != 0 {
+		return m.WinningTeam == team.Team
+	}
+	return m.WinnerID != "" && m.WinnerID == playerID
+}
+
+// MatchRules are how long each part of a match lasts, and the score that wins it early.
+type MatchRules struct {
+	Warmup     time.Duration
+	TimeLimit  time.Duration
+	EndScreen  time.Duration
+	ScoreLimit int32
+}
+
+var defaultMatchRules = MatchRules{
+	Warmup:     20 * time.Second,
+	TimeLimit:  5 * time.Minute,
+	EndScreen:  10 * time.Second,
+	ScoreLimit: 20,
+}
+
+// matchLeader is whoever is winning a match right now.
+type matchLeader struct {
+	PlayerID string
+	Name     string
+	Team     int32
+	Kills    int32
+}
+
+// findMatchLeader picks the player(or team) with the most kills.  Nobody leads when it is a tie.
+func findMatchLeader(entities map[sos.EntityID]*balancedEntity, mode GameMode) (matchLeader, bool) {
+	var leader matchLeader
+	found, tied := false, false
+
+	if mode.Teams > 0 {
+		for _, t := range buildTeamScores(entities, mode) {
+			switch {
+			case !found || t.Kills > leader.Kills:
+				leader = matchLeader{Team: t.Team, Name: TeamName(t.Team), Kills: t.Kills}
+				found, tied = true, false
+			case t.Kills == leader.Kills:
+				tied = true
+			}
+		}
+		return leader, found && !tied
+	}
+
+	for _, e := range entities {
+		if e.Client == "" {
+			continue
+		}
+		switch {
+		case !found || e.Score.Kills > leader.Kills:
+			name := e.Player.Name
+			if name == "" {
+				name = e.Client
+			}
+			leader = matchLeader{PlayerID: e.Player.PlayerID, Name: name, Kills: e.Score.Kills}
+			found, tied = true, false
+		case e.Score.Kills == leader.Kills:
+			tied = true
+		}
+	}
+	return leader, found && !tied
+}
+
+// advanceMatch moves m on to its next state when it is due.  leader is only used while running, and hasLeader is
+// false when nobody is ahead.
+func advanceMatch(m MatchComponent, now time.Time, rules MatchRules, leader matchLeader, hasLeader bool) MatchComponent {
+	due := unixMillis(now) >= m.StateEndsAt
+	switch m.State {
+	case MatchWarmup:
+		if m.StateEndsAt == 0 {
+			// A fresh match entity, start the clock.
+			m.StateEndsAt = unixMillis(now.Add(rules.Warmup))
+		} else if due {
+			m.State = MatchRunning
+			m.Round++
+			m.StateEndsAt = unixMillis(now.Add(rules.TimeLimit))
+			m.ScoreLimit = rules.ScoreLimit
+			m.WinnerID, m.WinnerName, m.WinningTeam = "", "", 0
+		}
+	case MatchRunning:
+		hitLimit := hasLeader && m.ScoreLimit > 0 && leader.Kills >= m.ScoreLimit
+		if hitLimit || due {
+			m.State = MatchEnded
+			m.StateEndsAt = unixMillis(now.Add(rules.EndScreen))
+			if hasLeader {
+				m.WinnerID, m.WinnerName, m.WinningTeam = leader.PlayerID, leader.Name, leader.Team
+			}
+		}
+	case MatchEnded:
+		if due {
+			m.State = MatchWarmup
+			m.StateEndsAt = unixMillis(now.Add(rules.Warmup))
+		}
+	}
+	return m
+}
+
+func unixMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// MatchSystem runs the balancer's match clock.
+type MatchSystem struct {
+	BS *BalancerScene
+}
+
+func (*MatchSystem) Remove(ecs.BasicEntity) {}
+func (ms *MatchSystem) Update(dt float32) {
+	ms.BS.updateMatch(time.Now())
+}
+
+// updateMatch advances the match, and pushes it out if it changed and we own it.
+func (bs *BalancerScene) updateMatch(now time.Time) {
+	if bs.MatchID == 0 {
+		return
+	}
+
+	leader, hasLeader := findMatchLeader(bs.Entities, bs.Mode)
+	m := advanceMatch(bs.Match, now, bs.MatchRules, leader, hasLeader)
+	if m == bs.Match {
+		return
+	}
+	if m.State != bs.Match.State {
+		log.Printf("Match %d: state %d -> %d, winner: %s", m.Round, bs.Match.State, m.State, m.WinnerName)
+	}
+	// Everyone starts the new round from zero.  The servers reset the ships they own, but we don't wait on them, or
+	// last round's leader would win this one on the next tick.  Players waiting to respawn start from zero too.
+	if m.Round != bs.Match.Round && m.State == MatchRunning {
+		for _, e := range bs.Entities {
+			e.Score = ScoreComponent{}
+		}
+		for _, session := range bs.Players {
+			if session.Respawn != nil {
+				session.Respawn = &ScoreComponent{}
+			}
+		}
+		bs.updateLeaderboard()
+	}
+	bs.Match = m
+	bs.spatial.UpdateComponent(bs.MatchID, cidMatch, bs.Match)
+}
+
+// setMatch is a server picking up a change to the match.  When a new round starts our ships start from zero.
+func (ss *ServerScene) setMatch(m MatchComponent) {
+	newRound := m.Round != ss.Match.Round && m.State == MatchRunning
+	ss.Match = m
+	if !newRound {
+		return
+	}
+
+	for _, e := range ss.Entities {
+		if ship, ok := e.(*Ship); ok && ship.HasAuthority {
+			ship.Score = ScoreComponent{}
+			ss.spatial.UpdateComponent(ship.ID, cidScore, ship.Score)
+		}
+	}
+}
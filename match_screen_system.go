@@ -0,0 +1,135 @@
+package superspatial
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+// MatchScreenSystem shows the match clock, and the You_Win or You_Lose screen once a match ends.
+type MatchScreenSystem struct {
+	Font *common.Font
+
+	Status Text
+	Window MenuSprite
+	Header MenuSprite
+	Stars  MenuSprite
+	Result Text
+
+	match  MatchComponent
+	won    bool
+	status string
+}
+
+// matchScreenAssets are the sprites the end of match screen is built from.
+var matchScreenAssets = []string{
+	"UI/You_Win/Window.png",
+	"UI/You_Win/Header.png",
+	"UI/You_Win/Star_03.png",
+	"UI/You_Lose/Window.png",
+	"UI/You_Lose/Header.png",
+	"UI/You_Lose/Star_01.png",
+}
+
+// Setup builds the screen, adding everything to rs and pinning it to the camera with hs.
+func (mss *MatchScreenSystem) Setup(rs *common.RenderSystem, hs *HudSystem) {
+	newSprite := func(width, height float32, zIndex float32) MenuSprite {
+		s := MenuSprite{BasicEntity: ecs.NewBasic()}
+		s.RenderComponent.Scale = engo.Point{X: 0.5, Y: 0.5}
+		s.RenderComponent.SetZIndex(zIndex)
+		s.RenderComponent.Hidden = true
+		s.SpaceComponent.Width = width
+		s.SpaceComponent.Height = height
+		return s
+	}
+	mss.Window = newSprite(470, 540, 200)
+	mss.Header = newSprite(226, 30, 201)
+	mss.Stars = newSprite(146, 140, 201)
+	mss.Header.RenderComponent.Scale = engo.Point{X: 1, Y: 1}
+
+	mss.Status = Text{BasicEntity: ecs.NewBasic()}
+	mss.Status.RenderComponent.Drawable = common.Text{Font: mss.Font, Text: " "}
+	mss.Status.RenderComponent.SetZIndex(100)
+	mss.Result = Text{BasicEntity: ecs.NewBasic()}
+	mss.Result.RenderComponent.Drawable = common.Text{Font: mss.Font, Text: " "}
+	mss.Result.RenderComponent.SetZIndex(202)
+	mss.Result.RenderComponent.Hidden = true
+
+	for _, s := range []*MenuSprite{&mss.Window, &mss.Header, &mss.Stars} {
+		rs.Add(&s.BasicEntity, &s.RenderComponent, &s.SpaceComponent)
+	}
+	for _, t := range []*Text{&mss.Status, &mss.Result} {
+		rs.Add(&t.BasicEntity, &t.RenderComponent, &t.SpaceComponent)
+	}
+	hs.Add(&mss.Status.BasicEntity, &mss.Status.RenderComponent, &mss.Status.SpaceComponent, engo.Point{X: -500, Y: -370})
+	hs.Add(&mss.Window.BasicEntity, &mss.Window.RenderComponent, &mss.Window.SpaceComponent, engo.Point{X: -235, Y: -270})
+	hs.Add(&mss.Header.BasicEntity, &mss.Header.RenderComponent, &mss.Header.SpaceComponent, engo.Point{X: -215, Y: -250})
+	hs.Add(&mss.Stars.BasicEntity, &mss.Stars.RenderComponent, &mss.Stars.SpaceComponent, engo.Point{X: -73, Y: -170})
+	hs.Add(&mss.Result.BasicEntity, &mss.Result.RenderComponent, &mss.Result.SpaceComponent, engo.Point{X: -180, Y: 10})
+}
+
+// SetMatch updates the match, won is if we won it when it has ended.
+func (mss *MatchScreenSystem) SetMatch(m MatchComponent, won bool) {
+	ended := m.State == MatchEnded
+	if ended && (mss.match.State != MatchEnded || mss.won != won) {
+		mss.showResult(m, won)
+	}
+	for _, r := range []*common.RenderComponent{&mss.Window.RenderComponent, &mss.Header.RenderComponent, &mss.Stars.RenderComponent, &mss.Result.RenderComponent} {
+		r.Hidden = !ended
+	}
+
+	mss.match = m
+	mss.won = won
+}
+
+func (mss *MatchScreenSystem) showResult(m MatchComponent, won bool) {
+	dir, stars := "UI/You_Lose/", "Star_01.png"
+	if won {
+		dir, stars = "UI/You_Win/", "Star_03.png"
+	}
+	for _, s := range []struct {
+		sprite *MenuSprite
+		file   string
+	}{{&mss.Window, "Window.png"}, {&mss.Header, "Header.png"}, {&mss.Stars, stars}} {
+		tex, err := common.LoadedSprite(dir + s.file)
+		if err != nil {
+			log.Printf("Unable to load %s: %v", dir+s.file, err)
+			continue
+		}
+		s.sprite.RenderComponent.Drawable = tex
+	}
+
+	result := "It's a draw!"
+	if m.WinnerName != "" {
+		result = m.WinnerName + " wins!"
+	}
+	mss.Result.RenderComponent.Drawable = common.Text{Font: mss.Font, Text: result}
+}
+
+func (*MatchScreenSystem) Remove(ecs.BasicEntity) {}
+func (mss *MatchScreenSystem) Update(dt float32) {
+	status := formatMatchStatus(mss.match, time.Now())
+	if status != mss.status {
+		mss.status = status
+		mss.Status.RenderComponent.Drawable = common.Text{Font: mss.Font, Text: status}
+	}
+}
+
+func formatMatchStatus(m MatchComponent, now time.Time) string {
+	left := m.Remaining(now)
+	clock := fmt.Sprintf("%d:%02d", int(left.Minutes()), int(left.Seconds())%60)
+	switch m.State {
+	case MatchRunning:
+		if m.ScoreLimit > 0 {
+			return fmt.Sprintf("Round %d  %s  First to %d", m.Round, clock, m.ScoreLimit)
+		}
+		return fmt.Sprintf("Round %d  %s", m.Round, clock)
+	case MatchEnded:
+		return "Next round in " + clock
+	default:
+		return "Warmup " + clock
+	}
+}
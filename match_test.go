@@ -0,0 +1,88 @@
+package superspatial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ScottBrooks/sos"
+)
+
+func TestAdvanceMatch(t *testing.T) {
+	rules := MatchRules{Warmup: 10 * time.Second, TimeLimit: time.Minute, EndScreen: 5 * time.Second, ScoreLimit: 3}
+	now := time.Unix(1000, 0)
+	leader := matchLeader{PlayerID: "worker:a", Name: "a", Kills: 1}
+
+	// A fresh match starts its warmup clock.
+	m := advanceMatch(MatchComponent{}, now, rules, leader, true)
+	if m.State != MatchWarmup || m.Remaining(now) != rules.Warmup {
+		t.Fatalf("expected warmup with %v left, got %+v", rules.Warmup, m)
+	}
+
+	// Nothing happens until warmup is over.
+	if next := advanceMatch(m, now.Add(time.Second), rules, leader, true); next != m {
+		t.Errorf("expected no change during warmup, got %+v", next)
+	}
+
+	now = now.Add(rules.Warmup)
+	m = advanceMatch(m, now, rules, leader, true)
+	if m.State != MatchRunning || m.Round != 1 || m.ScoreLimit != 3 {
+		t.Fatalf("expected round 1 to be running, got %+v", m)
+	}
+
+	// Hitting the score limit ends it early.
+	leader.Kills = 3
+	now = now.Add(time.Second)
+	m = advanceMatch(m, now, rules, leader, true)
+	if m.State != MatchEnded || m.WinnerID != "worker:a" || m.WinnerName != "a" {
+		t.Fatalf("expected a to win, got %+v", m)
+	}
+	if !m.Won("worker:a", TeamComponent{}) || m.Won("worker:b", TeamComponent{}) {
+		t.Errorf("expected only a to have won")
+	}
+
+	now = now.Add(rules.EndScreen)
+	m = advanceMatch(m, now, rules, leader, true)
+	if m.State != MatchWarmup {
+		t.Fatalf("expected to be back in warmup, got %+v", m)
+	}
+
+	// Running out of time with nobody ahead is a draw.
+	now = now.Add(rules.Warmup)
+	m = advanceMatch(m, now, rules, matchLeader{}, false)
+	now = now.Add(rules.TimeLimit)
+	m = advanceMatch(m, now, rules, matchLeader{}, false)
+	if m.State != MatchEnded || m.Round != 2 || m.WinnerID != "" || m.Won("", TeamComponent{}) {
+		t.Errorf("expected round 2 to end in a draw, got %+v", m)
+	}
+}
+
+func TestFindMatchLeader(t *testing.T) {
+	entities := map[sos.EntityID]*balancedEntity{
+		1: {Client: "a", Team: TeamComponent{1}, Score: ScoreComponent{Kills: 2}, Player: PlayerComponent{PlayerID: "worker:a", Name: "Ace"}},
+		2: {Client: "b", Team: TeamComponent{2}, Score: ScoreComponent{Kills: 1}},
+		3: {Client: "c", Team: TeamComponent{2}, Score: ScoreComponent{Kills: 2}},
+		// Not a player.
+		4: {Score: ScoreComponent{Kills: 10}},
+	}
+
+	if _, ok := findMatchLeader(entities, GameMode{}); ok {
+		t.Errorf("expected a tie between a and c")
+	}
+
+	entities[1].Score.Kills = 3
+	leader, ok := findMatchLeader(entities, GameMode{})
+	if !ok || leader.PlayerID != "worker:a" || leader.Name != "Ace" || leader.Kills != 3 {
+		t.Errorf("expected Ace to lead, got %+v %v", leader, ok)
+	}
+
+	leader, ok = findMatchLeader(entities, GameMode{Teams: 2})
+	if ok {
+		t.Errorf("expected the teams to be tied, got %+v", leader)
+	}
+
+	entities[2].Score.Kills = 5
+	leader, ok = findMatchLeader(entities, GameMode{Teams: 2})
+	if !ok || leader.Team != 2 || leader.Kills != 7 {
+		t.Errorf("expected team 2 to lead, got %+v %v", leader, ok)
+	}
+}
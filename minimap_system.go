@@ -0,0 +1,155 @@
+package superspatial
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+	"github.com/ScottBrooks/sos"
+)
+
+// How wide the minimap is on screen, it's as tall as it needs to be to keep the world's shape.
+const minimapWidth = 200
+
+var (
+	minimapBackground = color.RGBA{0, 0, 0, 160}
+	minimapBorder     = color.RGBA{255, 255, 255, 200}
+	minimapSelf       = color.RGBA{255, 255, 255, 255}
+	minimapTeammate   = color.RGBA{80, 160, 255, 255}
+	minimapEnemy      = color.RGBA{255, 60, 60, 255}
+	minimapEffect     = color.RGBA{255, 170, 0, 255}
+	minimapWorker     = color.RGBA{0, 255, 0, 120}
+)
+
+// MinimapSystem draws the whole world in the corner of the screen: our ship, everyone we can see, explosions, and the
+// server workers' areas when worker ids are turned on.
+type MinimapSystem struct {
+	CS     *ClientScene
+	Offset engo.Point
+
+	background MenuSprite
+	dots       map[sos.EntityID]*MenuSprite
+	workers    []*MenuSprite
+}
+
+func (ms *MinimapSystem) Setup() {
+	ms.dots = map[sos.EntityID]*MenuSprite{}
+	ms.background = MenuSprite{BasicEntity: ecs.NewBasic()}
+	ms.background.RenderComponent = common.RenderComponent{
+		Drawable: common.Rectangle{BorderWidth: 1, BorderColor: minimapBorder},
+		Color:    minimapBackground,
+		Scale:    engo.Point{X: 1, Y: 1},
+	}
+	ms.background.RenderComponent.SetZIndex(150)
+	ms.CS.R.Add(&ms.background.BasicEntity, &ms.background.RenderComponent, &ms.background.SpaceComponent)
+}
+
+// scale is how many screen pixels a world unit takes up on the minimap.
+func (ms *MinimapSystem) scale() float32 {
+	return minimapWidth / (worldBounds.Max.X - worldBounds.Min.X) * ms.CS.HS.scale()
+}
+
+// toMap places a point in the world on the minimap.
+func (ms *MinimapSystem) toMap(x, y float32) engo.Point {
+	origin := ms.background.SpaceComponent.Position
+	return engo.Point{X: origin.X + (x-worldBounds.Min.X)*ms.scale(), Y: origin.Y + (y-worldBounds.Min.Y)*ms.scale()}
+}
+
+func (ms *MinimapSystem) newDot(c color.Color, shape common.Drawable, zIndex float32) *MenuSprite {
+	dot := &MenuSprite{BasicEntity: ecs.NewBasic()}
+	dot.RenderComponent = common.RenderComponent{Drawable: shape, Color: c, Scale: engo.Point{X: 1, Y: 1}}
+	dot.RenderComponent.SetZIndex(zIndex)
+	ms.CS.R.Add(&dot.BasicEntity, &dot.RenderComponent, &dot.SpaceComponent)
+	return dot
+}
+
+func (*MinimapSystem) Remove(ecs.BasicEntity) {}
+func (ms *MinimapSystem) Update(dt float32) {
+	if ms.CS.HS.Camera == nil {
+		return
+	}
+	ms.background.SpaceComponent.Position = ms.CS.HS.ScreenPos(ms.Offset)
+	ms.background.SpaceComponent.Width = (worldBounds.Max.X - worldBounds.Min.X) * ms.scale()
+	ms.background.SpaceComponent.Height = (worldBounds.Max.Y - worldBounds.Min.Y) * ms.scale()
+
+	seen := map[sos.EntityID]bool{}
+	maxWorker := int32(-1)
+	for id, ship := range ms.CS.Ships {
+		seen[id] = true
+		dot, ok := ms.dots[id]
+		if !ok {
+			dot = ms.newDot(minimapEnemy, common.Rectangle{}, 152)
+			ms.dots[id] = dot
+		}
+
+		size := float32(4)
+		switch {
+		case id == ms.CS.PIS.ID:
+			dot.RenderComponent.Color = minimapSelf
+			size = 6
+		case sameTeam(ship.Team, ms.CS.Teams[ms.CS.PIS.ID]):
+			dot.RenderComponent.Color = minimapTeammate
+		default:
+			dot.RenderComponent.Color = minimapEnemy
+		}
+		ms.place(dot, ship.ShipComponent.Pos[0], ship.ShipComponent.Pos[1], size)
+
+		if ship.WorkerComponent.WorkerID > maxWorker {
+			maxWorker = ship.WorkerComponent.WorkerID
+		}
+	}
+	for id, effect := range ms.CS.Effects {
+		seen[id] = true
+		dot, ok := ms.dots[id]
+		if !ok {
+			dot = ms.newDot(minimapEffect, common.Circle{}, 151)
+			ms.dots[id] = dot
+		}
+		ms.place(dot, effect.EffectComponent.Pos[0], effect.EffectComponent.Pos[1], 8)
+	}
+
+	for id, dot := range ms.dots {
+		if !seen[id] {
+			ms.CS.R.Remove(dot.BasicEntity)
+			delete(ms.dots, id)
+		}
+	}
+
+	ms.updateWorkers(maxWorker)
+}
+
+// place centers dot on the minimap spot for x, y.
+func (ms *MinimapSystem) place(dot *MenuSprite, x, y float32, size float32) {
+	size *= ms.CS.HS.scale()
+	dot.SpaceComponent.Width = size
+	dot.SpaceComponent.Height = size
+	pos := ms.toMap(x, y)
+	dot.SpaceComponent.Position = engo.Point{X: pos.X - size/2, Y: pos.Y - size/2}
+}
+
+// updateWorkers outlines each server worker's area.  We only know workers by the ids on the ships we can see, so we
+// assume the balancer's square grid big enough to hold the highest one.
+func (ms *MinimapSystem) updateWorkers(maxWorker int32) {
+	var cells []engo.AABB
+	if ms.CS.Settings.ShowWorkerIDs && maxWorker >= 0 {
+		side := int(math.Ceil(math.Sqrt(float64(maxWorker + 1))))
+		cells = workerGrid(side*side, worldBounds)
+	}
+
+	for len(ms.workers) < len(cells) {
+		ms.workers = append(ms.workers, ms.newDot(color.Transparent, common.Rectangle{BorderWidth: 1, BorderColor: minimapWorker}, 151))
+	}
+	for i, w := range ms.workers {
+		w.RenderComponent.Hidden = i >= len(cells)
+		if i >= len(cells) {
+			continue
+		}
+		min := ms.toMap(cells[i].Min.X, cells[i].Min.Y)
+		max := ms.toMap(cells[i].Max.X, cells[i].Max.Y)
+		w.SpaceComponent.Position = min
+		w.SpaceComponent.Width = max.X - min.X
+		w.SpaceComponent.Height = max.Y - min.Y
+	}
+}
@@ -0,0 +1,60 @@
+package superspatial
+
+import (
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Within this many degrees of the cursor we ease off the turn, so the ship settles on it instead of wobbling past.
+const aimEaseAngle = 30
+
+// mouseButtons remembers which mouse buttons are held, engo only tells us about presses and releases.  It has to see
+// every frame's, or it misses them.
+type mouseButtons map[engo.MouseButton]bool
+
+func (mb mouseButtons) update(m engo.Mouse) {
+	switch m.Action {
+	case engo.Press:
+		mb[m.Button] = true
+	case engo.Release:
+		mb[m.Button] = false
+	}
+}
+
+// screenToWorld turns a point on screen into a point in the world, as seen through cam.
+func screenToWorld(cam *common.CameraSystem, x, y float32) mgl32.Vec3 {
+	if engo.CanvasWidth() > 0 && engo.CanvasHeight() > 0 {
+		x *= engo.GameWidth() / engo.CanvasWidth()
+		y *= engo.GameHeight() / engo.CanvasHeight()
+	}
+	return mgl32.Vec3{
+		cam.X() + (x-engo.GameWidth()/2)*cam.Z(),
+		cam.Y() + (y-engo.GameHeight()/2)*cam.Z(),
+		0,
+	}
+}
+
+// aimTurn is the Turn input that swings self round to face target.  The server still does the turning, we only ask.
+func aimTurn(self ShipComponent, target mgl32.Vec3) float32 {
+	delta := angleDelta(self.Angle, headingAngle(target.Sub(self.Pos)))
+	return clampUnit(delta / aimEaseAngle)
+}
+
+// mouseAim steers p toward the cursor.  The left button thrusts and the right attacks, the keyboard still works too.
+func (pis *PlayerInputSystem) mouseAim(p *PlayerInputComponent) {
+	ship, ok := pis.Ships[pis.ID]
+	if !ok || pis.Camera == nil {
+		return
+	}
+
+	if p.Turn == 0 {
+		cursor := screenToWorld(pis.Camera, engo.Input.Mouse.X, engo.Input.Mouse.Y)
+		p.Turn = aimTurn(ship.ShipComponent, cursor)
+	}
+	if p.Throttle == 0 && pis.mouse[engo.MouseButtonLeft] {
+		p.Throttle = 1
+	}
+	p.Attack = p.Attack || pis.mouse[engo.MouseButtonRight]
+	p.setButtons()
+}
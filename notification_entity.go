@@ -0,0 +1,99 @@
+package superspatial
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/EngoEngine/engo"
+	"github.com/ScottBrooks/sos"
+)
+
+// Kinds of notification.
+const (
+	NotifyKill int32 = iota + 1
+	NotifyJoin
+	NotifyLeave
+	NotifyStreak
+)
+
+// How long a notification entity sticks around, in milliseconds.  Clients fade them out on their own.
+const notificationExpiry = 5000
+
+type NotificationComponent struct {
+	Kind    int32
+	Subject string
+	Object  string
+	Count   int32
+	Expiry  int32
+}
+
+func (n NotificationComponent) String() string {
+	switch n.Kind {
+	case NotifyKill:
+		return fmt.Sprintf("%s destroyed %s", n.Subject, n.Object)
+	case NotifyJoin:
+		return fmt.Sprintf("%s joined", n.Subject)
+	case NotifyLeave:
+		return fmt.Sprintf("%s left", n.Subject)
+	case NotifyStreak:
+		return fmt.Sprintf("%s is on a %d kill streak!", n.Subject, n.Count)
+	}
+	return n.Subject
+}
+
+// Notification is a short lived entity that tells every client something happened.
+type Notification struct {
+	ID           sos.EntityID
+	Meta         ImprobableMetadata    `sos:"53"`
+	ACL          ImprobableACL         `sos:"50"`
+	Pos          ImprobablePosition    `sos:"54"`
+	Notification NotificationComponent `sos:"1013"`
+}
+
+// Notify sends n to every client, deleting it again once it has expired.
+func (ss *ServerScene) Notify(n NotificationComponent) {
+	readAttrSet := []WorkerAttributeSet{
+		{[]string{"client"}},
+		{[]string{"position"}},
+		{[]string{"balancer"}},
+	}
+	readAcl := WorkerRequirementSet{AttributeSet: readAttrSet}
+	// We don't know which balancer leads, it claims these when the entity turns up.
+	writeAcl := map[uint32]WorkerRequirementSet{
+		cidNotification: WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidPosition:     WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidACL:          WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+	}
+
+	n.Expiry = notificationExpiry
+	log.Printf("Notify: %s", n)
+	ent := Notification{
+		ACL:          ImprobableACL{ComponentWriteAcl: writeAcl, ReadAcl: readAcl},
+		Meta:         ImprobableMetadata{Name: "Notification"},
+		Notification: n,
+	}
+	reqID := ss.spatial.CreateEntity(ent)
+	ss.OnCreateFunc[reqID] = func(ID sos.EntityID) {
+		go func() {
+			time.Sleep(time.Duration(n.Expiry) * time.Millisecond)
+
+			engo.Mailbox.Dispatch(DeleteEntityMessage{ID: ID})
+		}()
+	}
+}
+
+// streakMilestone reports if a kill streak of n is worth telling everyone about.
+func streakMilestone(n int32) bool {
+	return n == 3 || (n >= 5 && n%5 == 0)
+}
+
+// shipName is what we call a ship in notifications.
+func shipName(s *Ship) string {
+	if s.Appearance.Name != "" {
+		return s.Appearance.Name
+	}
+	if s.Player.Name != "" {
+		return s.Player.Name
+	}
+	return fmt.Sprintf("Ship %d", s.ID)
+}
@@ -0,0 +1,53 @@
+package superspatial
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotificationString(t *testing.T) {
+	var tests = []struct {
+		n    NotificationComponent
+		want string
+	}{
+		{NotificationComponent{Kind: NotifyKill, Subject: "Alice", Object: "Bob"}, "Alice destroyed Bob"},
+		{NotificationComponent{Kind: NotifyJoin, Subject: "Alice"}, "Alice joined"},
+		{NotificationComponent{Kind: NotifyLeave, Subject: "Bob"}, "Bob left"},
+		{NotificationComponent{Kind: NotifyStreak, Subject: "Alice", Count: 5}, "Alice is on a 5 kill streak!"},
+	}
+	for _, tt := range tests {
+		if got := tt.n.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestStreakMilestone(t *testing.T) {
+	milestones := []int32{}
+	for n := int32(0); n <= 20; n++ {
+		if streakMilestone(n) {
+			milestones = append(milestones, n)
+		}
+	}
+	want := []int32{3, 5, 10, 15, 20}
+	if len(milestones) != len(want) {
+		t.Fatalf("got %v, want %v", milestones, want)
+	}
+	for i := range want {
+		if milestones[i] != want[i] {
+			t.Fatalf("got %v, want %v", milestones, want)
+		}
+	}
+}
+
+func TestFeedAlpha(t *testing.T) {
+	if a := feedAlpha(0); a != 255 {
+		t.Errorf("new entry alpha %d, want 255", a)
+	}
+	if a := feedAlpha(killFeedLife - killFeedFade/2); a < 120 || a > 135 {
+		t.Errorf("fading entry alpha %d, want about half", a)
+	}
+	if a := feedAlpha(killFeedLife + time.Second); a != 0 {
+		t.Errorf("expired entry alpha %d, want 0", a)
+	}
+}
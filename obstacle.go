@@ -0,0 +1,261 @@
+package superspatial
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+	"github.com/ScottBrooks/sos"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// How close bots let themselves get to an obstacle before steering around it.
+const obstacleMargin = 64
+
+var obstacleColor = color.RGBA{120, 110, 100, 255}
+
+// ObstacleComponent is a piece of static world geometry, like an asteroid.  Vertices are x,y pairs relative to Pos
+// making a convex polygon, when there aren't any the obstacle is a circle of Radius.
+type ObstacleComponent struct {
+	Pos      mgl32.Vec3
+	Radius   float32
+	Vertices []float32
+}
+
+// polygon is the obstacle's outline in world space, or nil for a circle.
+func (o ObstacleComponent) polygon() []mgl32.Vec2 {
+	if len(o.Vertices) < 6 {
+		return nil
+	}
+	points := make([]mgl32.Vec2, 0, len(o.Vertices)/2)
+	for i := 0; i+1 < len(o.Vertices); i += 2 {
+		points = append(points, mgl32.Vec2{o.Pos[0] + o.Vertices[i], o.Pos[1] + o.Vertices[i+1]})
+	}
+	return points
+}
+
+// BoundingRadius is the radius of a circle around Pos containing the whole obstacle.
+func (o ObstacleComponent) BoundingRadius() float32 {
+	r := o.Radius
+	for i := 0; i+1 < len(o.Vertices); i += 2 {
+		if l := (mgl32.Vec2{o.Vertices[i], o.Vertices[i+1]}).Len(); l > r {
+			r = l
+		}
+	}
+	return r
+}
+
+// contact reports how far a circle at pos overlaps the obstacle, and the direction to push it to get it clear.
+func (o ObstacleComponent) contact(pos mgl32.Vec3, radius float32) (mgl32.Vec3, float32, bool) {
+	points := o.polygon()
+	if points == nil {
+		delta := pos.Sub(o.Pos)
+		delta[2] = 0
+		depth := o.Radius + radius - delta.Len()
+		if depth <= 0 {
+			return mgl32.Vec3{}, 0, false
+		}
+		if delta.Len() == 0 {
+			return mgl32.Vec3{1, 0, 0}, depth, true
+		}
+		return delta.Normalize(), depth, true
+	}
+
+	p := mgl32.Vec2{pos[0], pos[1]}
+	closest := points[0]
+	best := float32(math.MaxFloat32)
+	for i := range points {
+		c := closestOnSegment(p, points[i], points[(i+1)%len(points)])
+		if d := c.Sub(p).Len(); d < best {
+			closest, best = c, d
+		}
+	}
+
+	// Inside we have to go back out through the nearest edge, outside we're only touching if that edge is close.
+	var normal mgl32.Vec2
+	var depth float32
+	if pointInPolygon(p, points) {
+		normal, depth = closest.Sub(p), radius+best
+	} else {
+		normal, depth = p.Sub(closest), radius-best
+	}
+	if depth <= 0 {
+		return mgl32.Vec3{}, 0, false
+	}
+	if normal.Len() == 0 {
+		normal = p.Sub(mgl32.Vec2{o.Pos[0], o.Pos[1]})
+	}
+	if normal.Len() == 0 {
+		return mgl32.Vec3{1, 0, 0}, depth, true
+	}
+	return normal.Normalize().Vec3(0), depth, true
+}
+
+func closestOnSegment(p, a, b mgl32.Vec2) mgl32.Vec2 {
+	ab := b.Sub(a)
+	l := ab.Dot(ab)
+	if l == 0 {
+		return a
+	}
+	t := p.Sub(a).Dot(ab) / l
+	if t < 0 {
+		t = 0
+	}
+	if t > 1 {
+		t = 1
+	}
+	return a.Add(ab.Mul(t))
+}
+
+func pointInPolygon(p mgl32.Vec2, points []mgl32.Vec2) bool {
+	inside := false
+	for i, j := 0, len(points)-1; i < len(points); j, i = i, i+1 {
+		a, b := points[i], points[j]
+		if (a[1] > p[1]) != (b[1] > p[1]) && p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Obstacle is how a server worker keeps track of an obstacle in its region.
+type Obstacle struct {
+	ecs.BasicEntity
+	common.SpaceComponent
+
+	ID       sos.EntityID
+	Obstacle ObstacleComponent `sos:"1019"`
+	// Vel is always zero, obstacles don't move.
+	Vel mgl32.Vec3
+}
+
+// addObstacle starts colliding ships with an obstacle, the first time we see it.
+func (ss *ServerScene) addObstacle(ID sos.EntityID, c ObstacleComponent) {
+	if _, ok := ss.Entities[ID].(*Obstacle); ok {
+		return
+	}
+	ent := Obstacle{BasicEntity: ecs.NewBasic(), ID: ID, Obstacle: c}
+	ent.SpaceComponent.Position = engo.Point{X: c.Pos[0], Y: c.Pos[1]}
+	ss.Entities[ID] = &ent
+	ss.ECS[ent.BasicEntity.ID()] = &ent
+	ss.CircleCollisionSystem.Add(&ent.BasicEntity, &ent.SpaceComponent, c.BoundingRadius())
+	ss.phys.Add(&ent.BasicEntity, PhysicsBody{
+		Kind:    BodyObstacle,
+		Pos:     &ent.Obstacle.Pos,
+		Vel:     &ent.Vel,
+		Radius:  c.BoundingRadius(),
+		Outline: &ent.Obstacle,
+	})
+}
+
+func (ss *ServerScene) removeObstacle(ID sos.EntityID) {
+	ent, ok := ss.Entities[ID].(*Obstacle)
+	if !ok {
+		return
+	}
+	ss.CircleCollisionSystem.Remove(ent.BasicEntity)
+	ss.phys.Remove(ent.BasicEntity)
+	delete(ss.ECS, ent.BasicEntity.ID())
+	delete(ss.Entities, ID)
+}
+
+// obstacleCollision picks the ship and the obstacle out of a collision, if it was between one of each.
+func (ss *ServerScene) obstacleCollision(collision CircleCollisionMessage) (*Ship, *Obstacle, bool) {
+	if ship, ok := ss.ECS[collision.A.ID()].(*Ship); ok {
+		obstacle, ok := ss.ECS[collision.B.ID()].(*Obstacle)
+		return ship, obstacle, ok
+	}
+	if ship, ok := ss.ECS[collision.B.ID()].(*Ship); ok {
+		obstacle, ok := ss.ECS[collision.A.ID()].(*Obstacle)
+		return ship, obstacle, ok
+	}
+	return nil, nil, false
+}
+
+// avoidObstacles steers away from the obstacle our current velocity takes us deepest within margin of.
+func avoidObstacles(self ShipComponent, obstacles []ObstacleComponent, margin float32) mgl32.Vec3 {
+	ahead := self.Pos.Add(self.Vel.Mul(wallLookahead))
+
+	var steer mgl32.Vec3
+	var deepest float32
+	for _, o := range obstacles {
+		normal, depth, hit := o.contact(ahead, self.Radius+margin)
+		if hit && depth > deepest {
+			steer, deepest = normal, depth
+		}
+	}
+	return steer
+}
+
+type ClientObstacle struct {
+	ecs.BasicEntity
+	common.RenderComponent
+	common.SpaceComponent
+
+	ObstacleComponent
+
+	seam *seamView
+	// offset is where the corner of the drawing is, from Pos.
+	offset engo.Point
+}
+
+// Predict keeps the obstacle drawn on the same side of the seam as our ship.  Obstacles don't move, but we do.
+func (co *ClientObstacle) Predict(dt float32) {
+	pos := co.seam.place(co.ObstacleComponent.Pos)
+	co.SpaceComponent.Position = engo.Point{X: pos[0] + co.offset.X, Y: pos[1] + co.offset.Y}
+}
+
+// obstacleShape is what to draw for an obstacle, and the box in the world to draw it in.  Polygons are split into a
+// fan of triangles with their points scaled to the box, the way ComplexTriangles wants them.
+func obstacleShape(o ObstacleComponent) (common.Drawable, engo.AABB) {
+	points := o.polygon()
+	if points == nil {
+		return common.Circle{}, engo.AABB{
+			Min: engo.Point{X: o.Pos[0] - o.Radius, Y: o.Pos[1] - o.Radius},
+			Max: engo.Point{X: o.Pos[0] + o.Radius, Y: o.Pos[1] + o.Radius},
+		}
+	}
+
+	box := engo.AABB{Min: engo.Point{X: points[0][0], Y: points[0][1]}, Max: engo.Point{X: points[0][0], Y: points[0][1]}}
+	for _, p := range points {
+		box.Min.X = float32(math.Min(float64(box.Min.X), float64(p[0])))
+		box.Min.Y = float32(math.Min(float64(box.Min.Y), float64(p[1])))
+		box.Max.X = float32(math.Max(float64(box.Max.X), float64(p[0])))
+		box.Max.Y = float32(math.Max(float64(box.Max.Y), float64(p[1])))
+	}
+	scaled := func(p mgl32.Vec2) engo.Point {
+		return engo.Point{X: (p[0] - box.Min.X) / (box.Max.X - box.Min.X), Y: (p[1] - box.Min.Y) / (box.Max.Y - box.Min.Y)}
+	}
+
+	triangles := []engo.Point{}
+	for i := 1; i+1 < len(points); i++ {
+		triangles = append(triangles, scaled(points[0]), scaled(points[i]), scaled(points[i+1]))
+	}
+	return common.ComplexTriangles{Points: triangles}, box
+}
+
+func (cs *ClientScene) NewObstacle(o *ObstacleComponent) *ClientObstacle {
+	obstacle := ClientObstacle{BasicEntity: ecs.NewBasic(), ObstacleComponent: *o, seam: &cs.Seam}
+
+	drawable, box := obstacleShape(*o)
+	obstacle.offset = engo.Point{X: box.Min.X - o.Pos[0], Y: box.Min.Y - o.Pos[1]}
+	obstacle.RenderComponent = common.RenderComponent{
+		Drawable: drawable,
+		Color:    obstacleColor,
+		Scale:    engo.Point{X: 1, Y: 1},
+	}
+	obstacle.SpaceComponent = common.SpaceComponent{
+		Position: box.Min,
+		Width:    box.Max.X - box.Min.X,
+		Height:   box.Max.Y - box.Min.Y,
+	}
+	// Behind effects and ships.
+	obstacle.RenderComponent.SetZIndex(8)
+
+	cs.R.Add(&obstacle.BasicEntity, &obstacle.RenderComponent, &obstacle.SpaceComponent)
+	cs.CPS.Add(&obstacle)
+
+	return &obstacle
+}
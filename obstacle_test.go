@@ -0,0 +1,97 @@
+package superspatial
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/engo/common"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// A 200x200 square centred on 500,500.
+var squareObstacle = ObstacleComponent{Pos: mgl32.Vec3{500, 500, 0}, Vertices: []float32{-100, -100, 100, -100, 100, 100, -100, 100}}
+
+func TestObstacleContactCircle(t *testing.T) {
+	rock := ObstacleComponent{Pos: mgl32.Vec3{100, 100, 0}, Radius: 50}
+
+	if _, _, hit := rock.contact(mgl32.Vec3{200, 100, 0}, 32); hit {
+		t.Errorf("expected no contact 100 away")
+	}
+
+	normal, depth, hit := rock.contact(mgl32.Vec3{170, 100, 0}, 32)
+	if !hit {
+		t.Fatalf("expected contact 70 away")
+	}
+	if depth != 12 {
+		t.Errorf("got depth %f, want 12", depth)
+	}
+	if normal != (mgl32.Vec3{1, 0, 0}) {
+		t.Errorf("got normal %v, want straight out the right", normal)
+	}
+}
+
+func TestObstacleContactPolygon(t *testing.T) {
+	if _, _, hit := squareObstacle.contact(mgl32.Vec3{700, 500, 0}, 32); hit {
+		t.Errorf("expected no contact 100 off the right edge")
+	}
+
+	normal, depth, hit := squareObstacle.contact(mgl32.Vec3{620, 500, 0}, 32)
+	if !hit || depth != 12 || normal != (mgl32.Vec3{1, 0, 0}) {
+		t.Errorf("touching the right edge: got %v %f %v", normal, depth, hit)
+	}
+
+	// Just inside the top edge, we should go back out the top rather than through the middle.
+	normal, depth, hit = squareObstacle.contact(mgl32.Vec3{500, 410, 0}, 32)
+	if !hit || depth != 42 || normal != (mgl32.Vec3{0, -1, 0}) {
+		t.Errorf("inside the top edge: got %v %f %v", normal, depth, hit)
+	}
+}
+
+func TestObstacleBoundingRadius(t *testing.T) {
+	if r := (ObstacleComponent{Radius: 40}).BoundingRadius(); r != 40 {
+		t.Errorf("got %f for a circle, want 40", r)
+	}
+	if r := (ObstacleComponent{Vertices: []float32{-30, 0, 0, 40, 30, 0}}).BoundingRadius(); r != 40 {
+		t.Errorf("got %f for a triangle, want 40", r)
+	}
+}
+
+func TestAvoidObstacles(t *testing.T) {
+	obstacles := []ObstacleComponent{squareObstacle}
+
+	self := ShipComponent{Pos: mgl32.Vec3{100, 100, 0}, Vel: mgl32.Vec3{100, 0, 0}, Radius: 32}
+	if steer := avoidObstacles(self, obstacles, 64); steer.Len() != 0 {
+		t.Errorf("expected nothing to avoid, got %v", steer)
+	}
+
+	self = ShipComponent{Pos: mgl32.Vec3{250, 500, 0}, Vel: mgl32.Vec3{200, 0, 0}, Radius: 32}
+	if steer := avoidObstacles(self, obstacles, 64); steer[0] >= 0 {
+		t.Errorf("expected to steer back from the square, got %v", steer)
+	}
+}
+
+func TestObstacleShape(t *testing.T) {
+	drawable, box := obstacleShape(ObstacleComponent{Pos: mgl32.Vec3{100, 100, 0}, Radius: 50})
+	if _, ok := drawable.(common.Circle); !ok {
+		t.Errorf("expected a circle, got %T", drawable)
+	}
+	if box.Min.X != 50 || box.Max.Y != 150 {
+		t.Errorf("got box %+v", box)
+	}
+
+	drawable, box = obstacleShape(squareObstacle)
+	triangles, ok := drawable.(common.ComplexTriangles)
+	if !ok {
+		t.Fatalf("expected triangles, got %T", drawable)
+	}
+	if len(triangles.Points) != 6 {
+		t.Errorf("got %d points, want 2 triangles", len(triangles.Points))
+	}
+	for _, p := range triangles.Points {
+		if p.X < 0 || p.X > 1 || p.Y < 0 || p.Y > 1 {
+			t.Errorf("point %+v isn't scaled to the box", p)
+		}
+	}
+	if box.Min.X != 400 || box.Max.X != 600 {
+		t.Errorf("got box %+v", box)
+	}
+}
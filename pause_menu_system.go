@@ -0,0 +1,308 @@
+package superspatial
+
+import (
+	"fmt"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+// ClientSettings are the options on the settings screen.
+type ClientSettings struct {
+	ShowWorkerIDs bool
+	// MouseAim turns the ship toward the cursor instead of with the turn keys.
+	MouseAim bool
+	// Volume is a percentage, there is no sound yet.
+	Volume  int
+	UIScale float32
+}
+
+var DefaultClientSettings = ClientSettings{ShowWorkerIDs: true, Volume: 100, UIScale: 1}
+
+var volumeSteps = []int{0, 25, 50, 75, 100}
+var uiScaleSteps = []float32{0.75, 1, 1.25, 1.5}
+
+// How many selectable rows a page of the menu can have.
+const pauseMenuRows = 6
+
+// pauseMenuAssets are the sprites the pause and settings windows are built from.
+var pauseMenuAssets = []string{
+	"UI/Pause/Window.png",
+	"UI/Pause/Header.png",
+	"UI/Setting/Window.png",
+	"UI/Setting/Header.png",
+}
+
+type pauseMenuItem struct {
+	label func() string
+	exec  func()
+}
+
+// PauseMenuSystem is the overlay that opens with the Pause button(Escape).  The world keeps going underneath, we just
+// stop flying the ship.
+type PauseMenuSystem struct {
+	Font     *common.Font
+	PIS      *PlayerInputSystem
+	Bindings *Bindings
+	Settings *ClientSettings
+	// OnSettingsChanged is called whenever a setting is changed.
+	OnSettingsChanged func()
+	// OnBindingsChanged is called after a control is rebound, so the new keys can be saved.
+	OnBindingsChanged func()
+	// OnQuit leaves the game and goes back to the main menu.
+	OnQuit func()
+	// Chat gets Escape while it's open, so closing it doesn't pop the menu up.
+	Chat *ChatSystem
+
+	Open bool
+	// rebinding is the control waiting for a new key.
+	rebinding string
+
+	window    MenuSprite
+	header    MenuSprite
+	info      Text
+	rows      [pauseMenuRows]Text
+	items     []pauseMenuItem
+	selection SelectionSystem
+}
+
+// Setup builds the menu, adding everything to rs and pinning it to the camera with hs.
+func (pms *PauseMenuSystem) Setup(rs *common.RenderSystem, hs *HudSystem) {
+	pms.window = MenuSprite{BasicEntity: ecs.NewBasic()}
+	pms.window.RenderComponent.Scale = engo.Point{X: 0.5, Y: 0.5}
+	pms.window.RenderComponent.SetZIndex(300)
+	pms.header = MenuSprite{BasicEntity: ecs.NewBasic()}
+	pms.header.RenderComponent.Scale = engo.Point{X: 1, Y: 1}
+	pms.header.RenderComponent.SetZIndex(301)
+
+	pms.info = Text{BasicEntity: ecs.NewBasic()}
+	pms.info.RenderComponent.Drawable = common.Text{Font: pms.Font, Text: " "}
+	pms.info.RenderComponent.SetZIndex(302)
+
+	rs.Add(&pms.window.BasicEntity, &pms.window.RenderComponent, &pms.window.SpaceComponent)
+	rs.Add(&pms.header.BasicEntity, &pms.header.RenderComponent, &pms.header.SpaceComponent)
+	rs.Add(&pms.info.BasicEntity, &pms.info.RenderComponent, &pms.info.SpaceComponent)
+	hs.Add(&pms.window.BasicEntity, &pms.window.RenderComponent, &pms.window.SpaceComponent, engo.Point{X: -235, Y: -300})
+	hs.Add(&pms.header.BasicEntity, &pms.header.RenderComponent, &pms.header.SpaceComponent, engo.Point{X: -200, Y: -280})
+	hs.Add(&pms.info.BasicEntity, &pms.info.RenderComponent, &pms.info.SpaceComponent, engo.Point{X: -190, Y: 150})
+
+	for i := range pms.rows {
+		row := &pms.rows[i]
+		*row = Text{BasicEntity: ecs.NewBasic()}
+		row.RenderComponent.Drawable = common.Text{Font: pms.Font, Text: " "}
+		row.RenderComponent.SetZIndex(302)
+		row.SpaceComponent.Width = 380
+		row.SpaceComponent.Height = 40
+		rs.Add(&row.BasicEntity, &row.RenderComponent, &row.SpaceComponent)
+		hs.Add(&row.BasicEntity, &row.RenderComponent, &row.SpaceComponent, engo.Point{X: -190, Y: float32(-180 + i*55)})
+	}
+
+	pms.hide()
+}
+
+func (*PauseMenuSystem) Remove(ecs.BasicEntity) {}
+func (pms *PauseMenuSystem) Update(dt float32) {
+	if pms.Chat != nil && pms.Chat.Open {
+		return
+	}
+	if pms.rebinding != "" {
+		pms.readRebind()
+		return
+	}
+	if engo.Input.Button("Pause").JustPressed() {
+		if pms.Open {
+			pms.Close()
+		} else {
+			pms.showMain()
+		}
+		return
+	}
+	if pms.Open {
+		pms.selection.Update(dt)
+	}
+}
+
+// Close hides the menu and gives the controls back to the ship.
+func (pms *PauseMenuSystem) Close() {
+	pms.hide()
+}
+
+func (pms *PauseMenuSystem) hide() {
+	pms.Open = false
+	pms.rebinding = ""
+	pms.PIS.Paused = false
+	pms.selection.Reset()
+	pms.window.RenderComponent.Hidden = true
+	pms.header.RenderComponent.Hidden = true
+	pms.info.RenderComponent.Hidden = true
+	for i := range pms.rows {
+		pms.rows[i].RenderComponent.Hidden = true
+	}
+}
+
+func (pms *PauseMenuSystem) showMain() {
+	pms.show("UI/Pause/", " ", []pauseMenuItem{
+		{label: func() string { return "Resume" }, exec: pms.Close},
+		{label: func() string { return "Settings" }, exec: pms.showSettings},
+		{label: func() string { return "Quit to Menu" }, exec: func() {
+			pms.hide()
+			pms.OnQuit()
+		}},
+	})
+}
+
+func (pms *PauseMenuSystem) showSettings() {
+	s := pms.Settings
+	pms.show("UI/Setting/", " ", []pauseMenuItem{
+		{
+			label: func() string { return "Show worker ids: " + onOff(s.ShowWorkerIDs) },
+			exec:  func() { s.ShowWorkerIDs = !s.ShowWorkerIDs },
+		},
+		{
+			label: func() string { return "Aim: " + aimMode(s.MouseAim) },
+			exec:  func() { s.MouseAim = !s.MouseAim },
+		},
+		{
+			label: func() string { return fmt.Sprintf("Volume: %d%%", s.Volume) },
+			exec:  func() { s.Volume = nextInt(volumeSteps, s.Volume) },
+		},
+		{
+			label: func() string { return fmt.Sprintf("UI scale: %.0f%%", s.UIScale*100) },
+			exec:  func() { s.UIScale = nextFloat(uiScaleSteps, s.UIScale) },
+		},
+		{label: func() string { return "Controls" }, exec: pms.showControls},
+		{label: func() string { return "Back" }, exec: pms.showMain},
+	})
+}
+
+const controlsInfo = "Select a control, then press its new key"
+
+func (pms *PauseMenuSystem) showControls() {
+	items := []pauseMenuItem{}
+	for _, control := range ShipControls {
+		control := control
+		items = append(items, pauseMenuItem{
+			label: func() string {
+				if pms.rebinding == control {
+					return control + ": press a key"
+				}
+				return pms.Bindings.Label(control)
+			},
+			exec: func() {
+				pms.rebinding = control
+				pms.setInfo("Escape to cancel")
+			},
+		})
+	}
+	items = append(items, pauseMenuItem{label: func() string { return "Back" }, exec: pms.showSettings})
+	pms.show("UI/Setting/", controlsInfo, items)
+}
+
+// readRebind waits for a key to bind to the control being rebound.  Escape leaves it as it was.
+func (pms *PauseMenuSystem) readRebind() {
+	key, ok := PressedKey()
+	if !ok {
+		return
+	}
+	if key != "Escape" {
+		if err := pms.Bindings.Rebind(pms.rebinding, key); err != nil {
+			log.Printf("Unable to rebind %s: %v", pms.rebinding, err)
+		} else {
+			pms.Bindings.Register()
+			if pms.OnBindingsChanged != nil {
+				pms.OnBindingsChanged()
+			}
+		}
+	}
+	pms.rebinding = ""
+	pms.setInfo(controlsInfo)
+	pms.refresh()
+}
+
+// show opens a page of the menu, using the window from the UI folder dir.
+func (pms *PauseMenuSystem) show(dir string, info string, items []pauseMenuItem) {
+	pms.Open = true
+	pms.PIS.Paused = true
+	pms.items = items
+
+	for _, s := range []struct {
+		sprite *MenuSprite
+		file   string
+	}{{&pms.window, "Window.png"}, {&pms.header, "Header.png"}} {
+		tex, err := common.LoadedSprite(dir + s.file)
+		if err != nil {
+			log.Printf("Unable to load %s: %v", dir+s.file, err)
+			continue
+		}
+		s.sprite.RenderComponent.Drawable = tex
+		s.sprite.RenderComponent.Hidden = false
+	}
+	pms.setInfo(info)
+
+	pms.selection.Reset()
+	for i := range pms.rows {
+		row := &pms.rows[i]
+		row.RenderComponent.Hidden = i >= len(items)
+		if i >= len(items) {
+			continue
+		}
+		item := items[i]
+		row.RenderComponent.Drawable = common.Text{Font: pms.Font, Text: item.label()}
+		pms.selection.Add(&row.BasicEntity, &row.RenderComponent, func() {
+			item.exec()
+			if pms.Open {
+				pms.refresh()
+			}
+		})
+	}
+}
+
+func (pms *PauseMenuSystem) setInfo(info string) {
+	pms.info.RenderComponent.Drawable = common.Text{Font: pms.Font, Text: info}
+	pms.info.RenderComponent.Hidden = false
+}
+
+// refresh redraws the labels after a setting changed.
+func (pms *PauseMenuSystem) refresh() {
+	for i, item := range pms.items {
+		pms.rows[i].RenderComponent.Drawable = common.Text{Font: pms.Font, Text: item.label()}
+	}
+	if pms.OnSettingsChanged != nil {
+		pms.OnSettingsChanged()
+	}
+}
+
+func onOff(b bool) string {
+	if b {
+		return "On"
+	}
+	return "Off"
+}
+
+func aimMode(mouse bool) string {
+	if mouse {
+		return "Mouse"
+	}
+	return "Keys"
+}
+
+// nextInt is the step after v, wrapping around at the end.
+func nextInt(steps []int, v int) int {
+	for _, s := range steps {
+		if s > v {
+			return s
+		}
+	}
+	return steps[0]
+}
+
+// nextFloat is the step after v, wrapping around at the end.
+func nextFloat(steps []float32, v float32) float32 {
+	for _, s := range steps {
+		if s > v {
+			return s
+		}
+	}
+	return steps[0]
+}
@@ -2,12 +2,235 @@ package superspatial
 
 import (
 	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/go-gl/mathgl/mgl32"
 )
 
+// BodyKind picks the limits a body moves under.
+type BodyKind int
+
+const (
+	BodyShip BodyKind = iota
+	BodyProjectile
+	BodyObstacle
+)
+
+// bodyLimits is how each kind of body moves.  Damping is how much of its speed a body loses each second, and
+// Restitution how much of its closing speed it keeps when it bounces off something.
+type bodyLimits struct {
+	MaxSpeed       float32
+	MaxSpin        float32
+	Damping        float32
+	AngularDamping float32
+	Restitution    float32
+}
+
+var bodyKinds = map[BodyKind]bodyLimits{
+	BodyShip:       {MaxSpeed: maxShipSpeed, MaxSpin: maxTurnRate, Damping: 0.1, AngularDamping: 8, Restitution: 0.9},
+	BodyProjectile: {MaxSpeed: 900, Restitution: 0.5},
+	BodyObstacle:   {Restitution: 0.8},
+}
+
+// PhysicsBody is a circle PhysicsSystem moves around.  Pos, Vel, Angle and Spin point into the entity's own state, so
+// the system's changes show up wherever the entity keeps them.  Bodies without an Angle don't turn, and a zero Mass
+// never moves.
+type PhysicsBody struct {
+	Kind   BodyKind
+	Pos    *mgl32.Vec3
+	Vel    *mgl32.Vec3
+	Angle  *float32
+	Spin   *float32
+	Radius float32
+	Mass   float32
+	// Outline is the shape of a body that isn't just a circle of Radius.
+	Outline *ObstacleComponent
+	// Forces is called each step before the body moves, to push it with AddForce and AddTorque.
+	Forces func(b *PhysicsBody)
+
+	force  mgl32.Vec3
+	torque float32
+}
+
+// AddForce pushes the body this step.
+func (b *PhysicsBody) AddForce(f mgl32.Vec3) {
+	b.force = b.force.Add(f)
+}
+
+// AddTorque turns the body this step, positive is to the right.  Like force it is shared out by Mass.
+func (b *PhysicsBody) AddTorque(t float32) {
+	b.torque += t
+}
+
+func (b *PhysicsBody) invMass() float32 {
+	if b.Mass <= 0 {
+		return 0
+	}
+	return 1 / b.Mass
+}
+
+// integrate moves the body on by dt, using up the forces pushing it.
+func (b *PhysicsBody) integrate(dt float32) {
+	limits := bodyKinds[b.Kind]
+	if b.Forces != nil {
+		b.Forces(b)
+	}
+
+	*b.Vel = b.Vel.Add(b.force.Mul(b.invMass() * dt)).Mul(damping(limits.Damping, dt))
+	if b.Vel.Len() > limits.MaxSpeed {
+		*b.Vel = safeNormalize(*b.Vel).Mul(limits.MaxSpeed)
+	}
+	*b.Pos = b.Pos.Add(b.Vel.Mul(dt))
+
+	if b.Angle != nil && b.Spin != nil {
+		*b.Spin = (*b.Spin + b.torque*b.invMass()*dt) * damping(limits.AngularDamping, dt)
+		*b.Spin = mgl32.Clamp(*b.Spin, -limits.MaxSpin, limits.MaxSpin)
+		*b.Angle += *b.Spin * dt
+	}
+
+	b.force = mgl32.Vec3{}
+	b.torque = 0
+}
+
+// damping is what to scale speed by to lose rate of it a second, without overshooting at large dt.
+func damping(rate float32, dt float32) float32 {
+	return 1 / (1 + rate*dt)
+}
+
+// PhysicsSystem moves every body, pushes overlapping bodies apart trading momentum between them, and either bounces
+// them off the edge of the World or wraps them around it.
 type PhysicsSystem struct {
+	World *World
+
+	bodies   map[uint64]*PhysicsBody
+	contacts map[[2]uint64]bool
+}
+
+func (ps *PhysicsSystem) Add(ent *ecs.BasicEntity, body PhysicsBody) {
+	if ps.bodies == nil {
+		ps.bodies = map[uint64]*PhysicsBody{}
+	}
+	ps.bodies[ent.ID()] = &body
+}
+
+func (ps *PhysicsSystem) Remove(ent ecs.BasicEntity) {
+	delete(ps.bodies, ent.ID())
+}
+
+// Contact has a and b bounce off each other on the next Update.  Reporting the same pair twice only bounces them once.
+func (ps *PhysicsSystem) Contact(a, b ecs.BasicEntity) {
+	if ps.contacts == nil {
+		ps.contacts = map[[2]uint64]bool{}
+	}
+	key := [2]uint64{a.ID(), b.ID()}
+	if key[1] < key[0] {
+		key[0], key[1] = key[1], key[0]
+	}
+	ps.contacts[key] = true
 }
 
-func (*PhysicsSystem) Remove(ecs.BasicEntity) {}
+func (ps *PhysicsSystem) Update(dt float32) {
+	for key := range ps.contacts {
+		a, okA := ps.bodies[key[0]]
+		b, okB := ps.bodies[key[1]]
+		if okA && okB {
+			resolveContact(ps.World, a, b)
+		}
+	}
+	ps.contacts = nil
+
+	for _, b := range ps.bodies {
+		b.integrate(dt)
+		switch {
+		case ps.World == nil || b.invMass() == 0:
+		case ps.World.Wraps():
+			*b.Pos = ps.World.Wrap(*b.Pos)
+		default:
+			resolveWalls(b, ps.World.Bounds, bodyKinds[b.Kind].Restitution)
+		}
+	}
+}
+
+// overlap is how far a and b overlap, and the direction from a to b to push them apart along.  b is measured wherever
+// it's closest to a, which may be across the seam.
+func overlap(w *World, a, b *PhysicsBody) (mgl32.Vec3, float32, bool) {
+	bPos := a.Pos.Add(w.Delta(*a.Pos, *b.Pos))
+	if b.Outline != nil {
+		outline := *b.Outline
+		outline.Pos = bPos
+		normal, depth, hit := outline.contact(*a.Pos, a.Radius)
+		return normal.Mul(-1), depth, hit
+	}
+	if a.Outline != nil {
+		return a.Outline.contact(bPos, b.Radius)
+	}
+
+	delta := bPos.Sub(*a.Pos)
+	delta[2] = 0
+	dist := delta.Len()
+	depth := a.Radius + b.Radius - dist
+	if depth <= 0 {
+		return mgl32.Vec3{}, 0, false
+	}
+	if dist == 0 {
+		return mgl32.Vec3{1, 0, 0}, depth, true
+	}
+	return delta.Mul(1 / dist), depth, true
+}
+
+// resolveContact separates two overlapping bodies, the lighter one moving further, and exchanges the momentum along
+// the line between them.  The less bouncy of the two decides how much they bounce.
+func resolveContact(w *World, a, b *PhysicsBody) {
+	normal, depth, hit := overlap(w, a, b)
+	if !hit {
+		return
+	}
+	invA, invB := a.invMass(), b.invMass()
+	total := invA + invB
+	if total == 0 {
+		return
+	}
+	*a.Pos = a.Pos.Sub(normal.Mul(depth * invA / total))
+	*b.Pos = b.Pos.Add(normal.Mul(depth * invB / total))
+
+	// Already moving apart, don't pull them back together.
+	closing := b.Vel.Sub(*a.Vel).Dot(normal)
+	if closing >= 0 {
+		return
+	}
+	restitution := bodyKinds[a.Kind].Restitution
+	if r := bodyKinds[b.Kind].Restitution; r < restitution {
+		restitution = r
+	}
+	impulse := -(1 + restitution) * closing / total
+	*a.Vel = a.Vel.Sub(normal.Mul(impulse * invA))
+	*b.Vel = b.Vel.Add(normal.Mul(impulse * invB))
+}
+
+// resolveWalls keeps a body inside bounds.  Walls don't move, so it bounces off them the way it would an obstacle.
+func resolveWalls(b *PhysicsBody, bounds engo.AABB, restitution float32) {
+	if b.Pos[0] < bounds.Min.X {
+		b.Pos[0] = bounds.Min.X
+		*b.Vel = bounce(*b.Vel, mgl32.Vec3{1, 0, 0}, restitution)
+	}
+	if b.Pos[1] < bounds.Min.Y {
+		b.Pos[1] = bounds.Min.Y
+		*b.Vel = bounce(*b.Vel, mgl32.Vec3{0, 1, 0}, restitution)
+	}
+	if b.Pos[0] > bounds.Max.X {
+		b.Pos[0] = bounds.Max.X
+		*b.Vel = bounce(*b.Vel, mgl32.Vec3{-1, 0, 0}, restitution)
+	}
+	if b.Pos[1] > bounds.Max.Y {
+		b.Pos[1] = bounds.Max.Y
+		*b.Vel = bounce(*b.Vel, mgl32.Vec3{0, -1, 0}, restitution)
+	}
+}
 
-func (*PhysicsSystem) Update(dt float32) {
+// bounce reflects the part of vel heading into normal, keeping restitution of it.
+func bounce(vel mgl32.Vec3, normal mgl32.Vec3, restitution float32) mgl32.Vec3 {
+	into := vel.Dot(normal)
+	if into >= 0 {
+		return vel
+	}
+	return vel.Sub(normal.Mul((1 + restitution) * into))
 }
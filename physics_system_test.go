@@ -0,0 +1,144 @@
+package superspatial
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func newBody(pos, vel mgl32.Vec3, mass float32) *PhysicsBody {
+	return &PhysicsBody{Kind: BodyShip, Pos: &pos, Vel: &vel, Radius: 32, Mass: mass}
+}
+
+func TestResolveContactEqualMasses(t *testing.T) {
+	a := newBody(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{100, 0, 0}, 1000)
+	b := newBody(mgl32.Vec3{60, 0, 0}, mgl32.Vec3{-100, 0, 0}, 1000)
+
+	resolveContact(nil, a, b)
+
+	// Head on, they swap velocities less whatever the bounce loses.
+	if *a.Vel != (mgl32.Vec3{-90, 0, 0}) || *b.Vel != (mgl32.Vec3{90, 0, 0}) {
+		t.Errorf("got velocities %v %v", *a.Vel, *b.Vel)
+	}
+	if *a.Pos != (mgl32.Vec3{-2, 0, 0}) || *b.Pos != (mgl32.Vec3{62, 0, 0}) {
+		t.Errorf("expected to be pushed apart evenly, got %v %v", *a.Pos, *b.Pos)
+	}
+}
+
+func TestResolveContactConservesMomentum(t *testing.T) {
+	a := newBody(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{200, 0, 0}, 3000)
+	b := newBody(mgl32.Vec3{50, 10, 0}, mgl32.Vec3{0, 0, 0}, 1000)
+
+	before := a.Vel.Mul(a.Mass).Add(b.Vel.Mul(b.Mass))
+	resolveContact(nil, a, b)
+	after := a.Vel.Mul(a.Mass).Add(b.Vel.Mul(b.Mass))
+
+	if before.Sub(after).Len() > 0.01 {
+		t.Errorf("momentum went from %v to %v", before, after)
+	}
+	if b.Vel.Len() <= a.Vel.Len() {
+		t.Errorf("expected the light ship to be knocked away faster, got %v %v", *a.Vel, *b.Vel)
+	}
+}
+
+func TestResolveContactSeparating(t *testing.T) {
+	a := newBody(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{-50, 0, 0}, 1000)
+	b := newBody(mgl32.Vec3{60, 0, 0}, mgl32.Vec3{50, 0, 0}, 1000)
+
+	resolveContact(nil, a, b)
+
+	if *a.Vel != (mgl32.Vec3{-50, 0, 0}) || *b.Vel != (mgl32.Vec3{50, 0, 0}) {
+		t.Errorf("ships already moving apart shouldn't change speed, got %v %v", *a.Vel, *b.Vel)
+	}
+}
+
+func TestResolveWalls(t *testing.T) {
+	bounds := engo.AABB{Max: engo.Point{X: 1000, Y: 1000}}
+	b := newBody(mgl32.Vec3{1010, 500, 0}, mgl32.Vec3{100, 20, 0}, 1000)
+
+	resolveWalls(b, bounds, 0.5)
+
+	if *b.Pos != (mgl32.Vec3{1000, 500, 0}) {
+		t.Errorf("expected to be put back on the wall, got %v", *b.Pos)
+	}
+	if *b.Vel != (mgl32.Vec3{-50, 20, 0}) {
+		t.Errorf("expected to bounce off the wall, got %v", *b.Vel)
+	}
+}
+
+func TestPhysicsSystemContactOnce(t *testing.T) {
+	ps := PhysicsSystem{}
+	entA, entB := ecs.NewBasic(), ecs.NewBasic()
+	a := newBody(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{100, 0, 0}, 1000)
+	b := newBody(mgl32.Vec3{60, 0, 0}, mgl32.Vec3{-100, 0, 0}, 1000)
+	ps.Add(&entA, *a)
+	ps.Add(&entB, *b)
+
+	// The collision system reports each pair both ways round.
+	ps.Contact(entA, entB)
+	ps.Contact(entB, entA)
+	ps.Update(1.0 / 60)
+
+	if a.Vel[0] >= 0 || b.Vel[0] <= 0 {
+		t.Errorf("expected one bounce, got %v %v", *a.Vel, *b.Vel)
+	}
+
+	ps.Remove(entB)
+	ps.Contact(entA, entB)
+	ps.Update(1.0 / 60)
+	if a.Vel[0] >= 0 {
+		t.Errorf("expected no bounce off a removed body, got %v", *a.Vel)
+	}
+}
+
+func TestResolveContactObstacle(t *testing.T) {
+	ship := newBody(mgl32.Vec3{620, 500, 0}, mgl32.Vec3{-100, 0, 0}, 1000)
+	square := squareObstacle
+	rock := &PhysicsBody{Kind: BodyObstacle, Pos: &square.Pos, Vel: &mgl32.Vec3{}, Outline: &square}
+
+	resolveContact(nil, ship, rock)
+
+	if *ship.Pos != (mgl32.Vec3{632, 500, 0}) {
+		t.Errorf("expected to be pushed out of the square, got %v", *ship.Pos)
+	}
+	if *ship.Vel != (mgl32.Vec3{80, 0, 0}) {
+		t.Errorf("expected to bounce off the square, got %v", *ship.Vel)
+	}
+	if *rock.Pos != (mgl32.Vec3{500, 500, 0}) {
+		t.Errorf("obstacles don't move, got %v", *rock.Pos)
+	}
+}
+
+func TestIntegrateLimits(t *testing.T) {
+	b := newBody(mgl32.Vec3{}, mgl32.Vec3{}, 1000)
+	b.AddForce(mgl32.Vec3{1e9, 0, 0})
+	b.integrate(1)
+
+	if speed := b.Vel.Len(); speed != maxShipSpeed {
+		t.Errorf("got speed %f, want it capped at %d", speed, maxShipSpeed)
+	}
+
+	// Nothing pushing, damping slows us down.
+	before := b.Vel.Len()
+	b.integrate(1)
+	if b.Vel.Len() >= before {
+		t.Errorf("expected damping to slow us from %f, got %f", before, b.Vel.Len())
+	}
+}
+
+func TestBounce(t *testing.T) {
+	normal := mgl32.Vec3{-1, 0, 0}
+
+	vel := bounce(mgl32.Vec3{100, 50, 0}, normal, 0.5)
+	if vel != (mgl32.Vec3{-50, 50, 0}) {
+		t.Errorf("got %v, want to come back at half speed and keep sliding", vel)
+	}
+
+	// Already moving away, leave it alone.
+	vel = bounce(mgl32.Vec3{-100, 50, 0}, normal, 0.5)
+	if vel != (mgl32.Vec3{-100, 50, 0}) {
+		t.Errorf("got %v, want it unchanged", vel)
+	}
+}
@@ -7,3 +7,44 @@ type PlayerInput struct {
 	Forward bool
 	Back    bool
 }
+
+// Controls is how hard to thrust and turn, each from -1 to 1.  Throttle and Turn win when they're set, otherwise the
+// buttons are full thrust or full lock.
+func (p PlayerInputComponent) Controls() (throttle float32, turn float32) {
+	throttle, turn = clampUnit(p.Throttle), clampUnit(p.Turn)
+	if throttle == 0 {
+		if p.Forward {
+			throttle++
+		}
+		if p.Back {
+			throttle--
+		}
+	}
+	if turn == 0 {
+		if p.Left {
+			turn--
+		}
+		if p.Right {
+			turn++
+		}
+	}
+	return throttle, turn
+}
+
+// setButtons keeps the buttons in step with Throttle and Turn, for anything that only looks at those.
+func (p *PlayerInputComponent) setButtons() {
+	p.Forward = p.Throttle > 0
+	p.Back = p.Throttle < 0
+	p.Left = p.Turn < 0
+	p.Right = p.Turn > 0
+}
+
+func clampUnit(v float32) float32 {
+	if v > 1 {
+		return 1
+	}
+	if v < -1 {
+		return -1
+	}
+	return v
+}
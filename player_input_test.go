@@ -0,0 +1,80 @@
+package superspatial
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestPlayerInputControls(t *testing.T) {
+	var tests = []struct {
+		name           string
+		input          PlayerInputComponent
+		throttle, turn float32
+	}{
+		{"nothing", PlayerInputComponent{}, 0, 0},
+		{"buttons", PlayerInputComponent{Forward: true, Left: true}, 1, -1},
+		{"opposing buttons", PlayerInputComponent{Forward: true, Back: true, Left: true, Right: true}, 0, 0},
+		{"analog", PlayerInputComponent{Throttle: 0.5, Turn: -0.25}, 0.5, -0.25},
+		{"analog wins", PlayerInputComponent{Forward: true, Throttle: -0.5, Right: true, Turn: -1}, -0.5, -1},
+		{"clamped", PlayerInputComponent{Throttle: 3, Turn: -2}, 1, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttle, turn := tt.input.Controls()
+			if throttle != tt.throttle || turn != tt.turn {
+				t.Errorf("got %f, %f want %f, %f", throttle, turn, tt.throttle, tt.turn)
+			}
+		})
+	}
+}
+
+func TestApplyInputHalfThrottle(t *testing.T) {
+	full := NewShip(mgl32.Vec2{500, 500}, "")
+	half := NewShip(mgl32.Vec2{500, 500}, "")
+	full.PIC = PlayerInputComponent{Forward: true}
+	half.PIC = PlayerInputComponent{Throttle: 0.5}
+
+	ps := PhysicsSystem{}
+	ps.Add(&full.BasicEntity, full.body())
+	ps.Add(&half.BasicEntity, half.body())
+	ps.Update(0.1)
+
+	if got, want := half.Ship.Vel.Len(), full.Ship.Vel.Len()/2; got < want-0.01 || got > want+0.01 {
+		t.Errorf("expected half the speed at half throttle, got %f want %f", got, want)
+	}
+
+	full.PIC = PlayerInputComponent{Right: true}
+	half.PIC = PlayerInputComponent{Turn: 0.5}
+	// Long enough for the turn to settle.
+	for i := 0; i < 60; i++ {
+		ps.Update(1.0 / 60)
+	}
+	if got, want := full.Ship.Spin, float32(maxTurnRate); got < want-1 || got > want+1 {
+		t.Errorf("expected to turn at full lock, got %f want %f", got, want)
+	}
+	if got, want := half.Ship.Spin, float32(maxTurnRate*0.5); got < want-1 || got > want+1 {
+		t.Errorf("expected to turn half as fast, got %f want %f", got, want)
+	}
+}
+
+func TestAimTurn(t *testing.T) {
+	self := ShipComponent{Pos: mgl32.Vec3{100, 100, 0}, Angle: 0}
+	var tests = []struct {
+		name   string
+		target mgl32.Vec3
+		turn   float32
+	}{
+		{"dead ahead", mgl32.Vec3{200, 100, 0}, 0},
+		{"behind us on the right", mgl32.Vec3{0, 101, 0}, 1},
+		{"hard left", mgl32.Vec3{100, 0, 0}, -1},
+		{"a little right", mgl32.Vec3{200, 100 + 100*0.2679, 0}, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if turn := aimTurn(self, tt.target); turn < tt.turn-0.01 || turn > tt.turn+0.01 {
+				t.Errorf("got %f, want %f", turn, tt.turn)
+			}
+		})
+	}
+}
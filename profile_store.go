@@ -0,0 +1,112 @@
+package superspatial
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// How much a kill or death moves a player's rating.
+const ratingStep = 10
+const defaultRating = 1000
+
+var ErrProfileNotFound = errors.New("Profile not found")
+
+// PlayerProfile is everything we keep about a player between sessions.
+type PlayerProfile struct {
+	ID       string
+	Name     string
+	Color    string
+	Kills    int32
+	Deaths   int32
+	Rating   float32
+	LastSeen time.Time
+}
+
+// NewPlayerProfile is the profile for a player we've never seen before.
+func NewPlayerProfile(ID string, name string) PlayerProfile {
+	return PlayerProfile{ID: ID, Name: name, Rating: defaultRating}
+}
+
+// AddKills credits kills to the lifetime stats.
+func (p *PlayerProfile) AddKills(n int32) {
+	p.Kills += n
+	p.Rating += float32(n) * ratingStep
+}
+
+// AddDeath counts a death in the lifetime stats.
+func (p *PlayerProfile) AddDeath() {
+	p.Deaths++
+	p.Rating -= ratingStep
+	if p.Rating < 0 {
+		p.Rating = 0
+	}
+}
+
+// Component is the part of the profile that lives on a player's ship.
+func (p *PlayerProfile) Component() PlayerComponent {
+	return PlayerComponent{PlayerID: p.ID, Name: p.Name, Rating: p.Rating, LifetimeKills: p.Kills, LifetimeDeaths: p.Deaths}
+}
+
+// ProfileStore persists player profiles.
+type ProfileStore interface {
+	// Load returns ErrProfileNotFound for players that have never been saved.
+	Load(ID string) (PlayerProfile, error)
+	Save(p PlayerProfile) error
+}
+
+// FileProfileStore keeps one json file per player in Dir.  Good enough for development.
+type FileProfileStore struct {
+	Dir string
+}
+
+func (fps *FileProfileStore) path(ID string) string {
+	// Player ids come from identity providers, so encode them to get a safe file name.
+	return filepath.Join(fps.Dir, base64.RawURLEncoding.EncodeToString([]byte(ID))+".json")
+}
+
+func (fps *FileProfileStore) Load(ID string) (PlayerProfile, error) {
+	var p PlayerProfile
+
+	data, err := ioutil.ReadFile(fps.path(ID))
+	if os.IsNotExist(err) {
+		return p, ErrProfileNotFound
+	}
+	if err != nil {
+		return p, err
+	}
+
+	err = json.Unmarshal(data, &p)
+	return p, err
+}
+
+func (fps *FileProfileStore) Save(p PlayerProfile) error {
+	data, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(fps.Dir, 0755); err != nil {
+		return err
+	}
+
+	// Write then rename so a crash never leaves a half written profile.
+	path := fps.path(p.ID)
+	if err := ioutil.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// playerIdentity works out who a client is.  Clients that logged in with a player identity token are identified by
+// their provider and player identifier.  Anyone else(local development, bots) gets their worker id, which is new every
+// time they connect, so stable is false and there's no point saving a profile under it.
+func playerIdentity(workerID string, pc *ImprobablePlayerClient) (ID string, stable bool) {
+	if pc != nil && pc.PlayerIdentity.PlayerIdentifier != "" {
+		return pc.PlayerIdentity.Provider + ":" + pc.PlayerIdentity.PlayerIdentifier, true
+	}
+	return "worker:" + workerID, false
+}
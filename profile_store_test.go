@@ -0,0 +1,74 @@
+package superspatial
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFileProfileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	if err != nil {
+		t.Fatalf("unable to make temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := &FileProfileStore{Dir: dir}
+
+	_, err = store.Load("steam:1234")
+	if err != ErrProfileNotFound {
+		t.Fatalf("expected ErrProfileNotFound, got %v", err)
+	}
+
+	p := NewPlayerProfile("steam:1234", "Stabby")
+	p.Color = "red"
+	p.AddKills(3)
+	p.AddDeath()
+	if err := store.Save(p); err != nil {
+		t.Fatalf("unable to save: %v", err)
+	}
+
+	loaded, err := store.Load("steam:1234")
+	if err != nil {
+		t.Fatalf("unable to load: %v", err)
+	}
+	if loaded != p {
+		t.Errorf("got %+v, want %+v", loaded, p)
+	}
+	if loaded.Rating != defaultRating+2*ratingStep {
+		t.Errorf("got rating %f, want %d", loaded.Rating, defaultRating+2*ratingStep)
+	}
+}
+
+func TestOnlyIdentifiedPlayersSaved(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	if err != nil {
+		t.Fatalf("unable to make temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := &FileProfileStore{Dir: dir}
+	saved := NewPlayerProfile("steam:1234", "Stabby")
+	saved.AddKills(2)
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("unable to save: %v", err)
+	}
+
+	bs := &BalancerScene{Profiles: store, Players: map[string]*playerSession{}}
+	pc := &ImprobablePlayerClient{PlayerIdentity: ImprobablePlayerIdentity{Provider: "steam", PlayerIdentifier: "1234"}}
+	bs.loadPlayer("LauncherClient_1", "LauncherClient", pc)
+	if session := bs.Players["LauncherClient_1"]; !session.Persist || session.Profile != saved {
+		t.Errorf("expected to pick up the profile saved as steam:1234, got %+v", session)
+	}
+
+	// Without a token there's nothing to tell who they are, whatever they call themselves.
+	bs.loadPlayer("LauncherClient_2", "LauncherClient", nil)
+	if session := bs.Players["LauncherClient_2"]; session.Persist {
+		t.Errorf("expected an anonymous session that isn't saved, got %+v", session)
+	}
+
+	bs.loadPlayer("Bot_1", "Bot", nil)
+	if bot := bs.Players["Bot_1"]; bot.Persist {
+		t.Errorf("bots are never saved, got %+v", bot)
+	}
+}
@@ -0,0 +1,299 @@
+package superspatial
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+	"github.com/ScottBrooks/sos"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// How many random spawn points we try before picking the one furthest from everyone.
+	spawnCandidates = 16
+	// How many we try in all when they keep landing on obstacles.
+	maxSpawnTries = 256
+	// New ships can't hit or be hit for this long.
+	spawnProtection = 3 * time.Second
+	// How quickly protected ships flash on and off.
+	protectionFlash = 150 * time.Millisecond
+)
+
+// respawnCommand is sent to a player's session to ask for a new ship after they've been destroyed.
+var respawnCommand = CommandKey{cidSession, sessionRespawn}
+
+type RespawnRequest struct {
+	Seq int64
+}
+
+func (r RespawnRequest) RequestSeq() int64 { return r.Seq }
+
+type RespawnResponse struct {
+	Seq      int64
+	Accepted bool
+	Reason   string
+}
+
+func (r RespawnResponse) ResponseSeq() int64 { return r.Seq }
+
+// RespawnQueue and RespawnAcks carry respawnCommand when the runtime can't.
+type RespawnQueue struct {
+	Requests []RespawnRequest
+}
+
+func (q RespawnQueue) QueuedRequests() []CommandRequest {
+	reqs := make([]CommandRequest, len(q.Requests))
+	for i, r := range q.Requests {
+		reqs[i] = r
+	}
+	return reqs
+}
+
+func newRespawnQueue(reqs []CommandRequest) interface{} {
+	q := RespawnQueue{Requests: []RespawnRequest{}}
+	for _, r := range reqs {
+		q.Requests = append(q.Requests, r.(RespawnRequest))
+	}
+	return q
+}
+
+type RespawnAcks struct {
+	Responses []RespawnResponse
+}
+
+func (a RespawnAcks) AckedResponses() []CommandResponse {
+	resps := make([]CommandResponse, len(a.Responses))
+	for i, r := range a.Responses {
+		resps[i] = r
+	}
+	return resps
+}
+
+func newRespawnAcks(resps []CommandResponse) interface{} {
+	a := RespawnAcks{Responses: []RespawnResponse{}}
+	for _, r := range resps {
+		a.Responses = append(a.Responses, r.(RespawnResponse))
+	}
+	return a
+}
+
+// HandleRespawn answers respawnCommand with h.
+func (c *Commands) HandleRespawn(h func(ID sos.EntityID, req RespawnRequest) RespawnResponse) {
+	c.Handle(respawnCommand, func(ID sos.EntityID, req CommandRequest) CommandResponse {
+		r := req.(RespawnRequest)
+		resp := h(ID, r)
+		resp.Seq = r.Seq
+		return resp
+	})
+}
+
+// Respawn asks for a new ship through the session entity ID, done is called with the balancer's response once it's
+// back.
+func (ss *ServerScene) Respawn(ID sos.EntityID, done func(RespawnResponse, error)) {
+	req := RespawnRequest{Seq: ss.Commands.nextSeq()}
+	ss.sendCommand(ID, respawnCommand, req, func(r CommandResult) {
+		if r.Err != nil {
+			done(RespawnResponse{}, r.Err)
+			return
+		}
+		done(r.Response.(RespawnResponse), nil)
+	})
+}
+
+// Protected reports if the ship is still in its spawn protection.
+func (s ShipComponent) Protected(now time.Time) bool {
+	return unixMillis(now) < s.ProtectedUntil
+}
+
+// AttackReady reports if the ship's attack has recharged since its last hit.
+func (s ShipComponent) AttackReady(now time.Time) bool {
+	return unixMillis(now) >= s.AttackReadyAt
+}
+
+// AttackCharge is how far the ship's attack has recharged since its last hit, from 0 to 1.
+func (s ShipComponent) AttackCharge(now time.Time) float32 {
+	left := s.AttackReadyAt - unixMillis(now)
+	if left <= 0 {
+		return 1
+	}
+	return 1 - float32(left)/float32(attackCooldown/time.Millisecond)
+}
+
+// pickSpawnPoint tries a few random points in world, picking the one furthest from any of ships.  Distances go the
+// short way round, across the seam in a wrapping world.  Points where a ship would overlap one of obstacles don't
+// count, we keep trying until we find one that doesn't.
+func pickSpawnPoint(world *World, ships []mgl32.Vec2, obstacles []ObstacleComponent, rnd func() float32) mgl32.Vec2 {
+	bounds := world.Bounds
+	var best mgl32.Vec2
+	bestDist := float32(-1)
+	for i := 0; i < spawnCandidates || (bestDist < 0 && i < maxSpawnTries); i++ {
+		p := mgl32.Vec2{
+			bounds.Min.X + rnd()*(bounds.Max.X-bounds.Min.X),
+			bounds.Min.Y + rnd()*(bounds.Max.Y-bounds.Min.Y),
+		}
+		if blocked(world, p, obstacles) {
+			if bestDist < 0 {
+				best = p
+			}
+			continue
+		}
+		dist := float32(math.MaxFloat32)
+		for _, s := range ships {
+			if d := world.Distance(p.Vec3(0), s.Vec3(0)); d < dist {
+				dist = d
+			}
+		}
+		if dist > bestDist {
+			best, bestDist = p, dist
+		}
+	}
+	if bestDist < 0 {
+		log.Printf("Couldn't find a spawn point clear of obstacles, spawning at %v", best)
+	}
+	return best
+}
+
+// blocked reports if a ship at p would overlap any of obstacles.
+func blocked(world *World, p mgl32.Vec2, obstacles []ObstacleComponent) bool {
+	for _, o := range obstacles {
+		if world.Distance(p.Vec3(0), o.Pos.Vec2().Vec3(0)) < o.BoundingRadius()+shipRadius {
+			return true
+		}
+	}
+	return false
+}
+
+// spawnPoint picks somewhere for a new ship, away from the ships already out there and clear of obstacles.
+func (bs *BalancerScene) spawnPoint() mgl32.Vec2 {
+	ships := []mgl32.Vec2{}
+	obstacles := []ObstacleComponent{}
+	for _, e := range bs.Entities {
+		if e.Client != "" {
+			ships = append(ships, mgl32.Vec2{float32(e.Pos.Coords.X), float32(e.Pos.Coords.Z)})
+		}
+		if e.Obstacle.BoundingRadius() > 0 {
+			obstacles = append(obstacles, e.Obstacle)
+		}
+	}
+	return pickSpawnPoint(&bs.World, ships, obstacles, rand.Float32)
+}
+
+// playerDied holds on to a dead player's score until they ask to respawn.  Bots don't ask, they get a new ship
+// straight away.
+func (bs *BalancerScene) playerDied(workerID string, score ScoreComponent) {
+	session, ok := bs.Players[workerID]
+	if !ok || session.AutoRespawn {
+		bs.CreateClientShip(workerID, score)
+		return
+	}
+	session.Respawn = &score
+}
+
+// handleRespawn answers a respawn request written to the session entity ID, which only its player can write to.
+func (bs *BalancerScene) handleRespawn(ID sos.EntityID, req RespawnRequest) RespawnResponse {
+	workerID, ok := bs.sessions[ID]
+	if !ok {
+		return RespawnResponse{Reason: "Unknown session"}
+	}
+	session, ok := bs.Players[workerID]
+	if !ok {
+		return RespawnResponse{Reason: "Unknown player: " + workerID}
+	}
+	if session.Respawn == nil {
+		return RespawnResponse{Reason: "You already have a ship"}
+	}
+
+	bs.CreateClientShip(workerID, *session.Respawn)
+	session.Respawn = nil
+	return RespawnResponse{Accepted: true}
+}
+
+// requestRespawn asks the balancer for a new ship.
+func (cs *ClientScene) requestRespawn() {
+	if cs.SessionID == 0 {
+		cs.RS.Failed("Nowhere to respawn yet")
+		return
+	}
+	cs.Respawn(cs.SessionID, func(resp RespawnResponse, err error) {
+		if err != nil {
+			cs.RS.Failed(err.Error())
+			return
+		}
+		if !resp.Accepted {
+			cs.RS.Failed(resp.Reason)
+		}
+	})
+}
+
+// RespawnSystem tells the player they've been destroyed, and asks for a new ship when they press Attack.
+type RespawnSystem struct {
+	Font     *common.Font
+	PIS      *PlayerInputSystem
+	Bindings *Bindings
+	// Send asks for a new ship.
+	Send func()
+
+	Dead    bool
+	waiting bool
+	text    Text
+}
+
+// Setup builds the message, adding it to rs and pinning it at offset with hs.
+func (rs *RespawnSystem) Setup(r *common.RenderSystem, hs *HudSystem, offset engo.Point) {
+	rs.text = Text{BasicEntity: ecs.NewBasic()}
+	rs.text.RenderComponent.Drawable = common.Text{Font: rs.Font, Text: " "}
+	rs.text.RenderComponent.SetZIndex(100)
+	rs.text.RenderComponent.Hidden = true
+	r.Add(&rs.text.BasicEntity, &rs.text.RenderComponent, &rs.text.SpaceComponent)
+	hs.Add(&rs.text.BasicEntity, &rs.text.RenderComponent, &rs.text.SpaceComponent, offset)
+}
+
+// Died is our ship being destroyed.
+func (rs *RespawnSystem) Died() {
+	rs.Dead = true
+	rs.waiting = false
+	rs.show(respawnPrompt(rs.Bindings))
+}
+
+// Spawned is us getting a new ship.
+func (rs *RespawnSystem) Spawned() {
+	rs.Dead = false
+	rs.waiting = false
+	rs.text.RenderComponent.Hidden = true
+}
+
+// Failed is the balancer turning down our respawn.
+func (rs *RespawnSystem) Failed(reason string) {
+	rs.waiting = false
+	rs.show(reason + "\n" + respawnPrompt(rs.Bindings))
+}
+
+func (rs *RespawnSystem) show(text string) {
+	rs.text.RenderComponent.Drawable = common.Text{Font: rs.Font, Text: text}
+	rs.text.RenderComponent.Hidden = false
+}
+
+func (*RespawnSystem) Remove(ecs.BasicEntity) {}
+func (rs *RespawnSystem) Update(dt float32) {
+	if !rs.Dead || rs.waiting || rs.PIS.Paused || !engo.Input.Button("Attack").JustPressed() {
+		return
+	}
+	rs.waiting = true
+	rs.show("Respawning...")
+	if rs.Send != nil {
+		rs.Send()
+	}
+}
+
+func respawnPrompt(b *Bindings) string {
+	key := "Attack"
+	if b != nil && len(b.Keys["Attack"]) > 0 {
+		key = b.Keys["Attack"][0]
+	}
+	return fmt.Sprintf("Destroyed! Press %s to respawn", key)
+}
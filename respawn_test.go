@@ -0,0 +1,77 @@
+package superspatial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/EngoEngine/engo"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestPickSpawnPoint(t *testing.T) {
+	world := &World{Bounds: engo.AABB{Max: engo.Point{X: 1000, Y: 1000}}}
+
+	// Candidates walk along the diagonal, the ship sits near the start of it.
+	i := 0
+	rnd := func() float32 {
+		v := float32(i/2) / spawnCandidates
+		i++
+		return v
+	}
+	got := pickSpawnPoint(world, []mgl32.Vec2{{100, 100}}, nil, rnd)
+	want := float32(spawnCandidates-1) / spawnCandidates * 1000
+	if got != (mgl32.Vec2{want, want}) {
+		t.Errorf("got %v, want the candidate furthest from the ship at %v", got, want)
+	}
+
+	// With nobody around anywhere will do, but it has to be in bounds.
+	world = &World{Bounds: engo.AABB{Min: engo.Point{X: 100, Y: 200}, Max: engo.Point{X: 300, Y: 400}}}
+	got = pickSpawnPoint(world, nil, nil, func() float32 { return 0.5 })
+	if got != (mgl32.Vec2{200, 300}) {
+		t.Errorf("got %v, want the middle of the bounds", got)
+	}
+
+	// An asteroid over the middle pushes us off to the next candidate clear of it.
+	asteroid := ObstacleComponent{Pos: mgl32.Vec3{200, 300, 0}, Radius: 50}
+	candidates := []float32{0.5, 0.5, 0.2, 0.2}
+	i = 0
+	rnd = func() float32 {
+		v := candidates[i%len(candidates)]
+		i++
+		return v
+	}
+	got = pickSpawnPoint(world, nil, []ObstacleComponent{asteroid}, rnd)
+	if got != (mgl32.Vec2{140, 240}) {
+		t.Errorf("got %v, want the candidate clear of the asteroid", got)
+	}
+	if !blocked(world, mgl32.Vec2{200, 300 + 50 + shipRadius - 1}, []ObstacleComponent{asteroid}) {
+		t.Error("a ship overlapping the asteroid's edge isn't blocked")
+	}
+
+	// Across the seam the far end of the diagonal is right next to the ship, the middle is furthest.
+	world = &World{Bounds: engo.AABB{Max: engo.Point{X: 1000, Y: 1000}}, Topology: TopologyWrap}
+	i = 0
+	rnd = func() float32 {
+		v := float32(i/2) / spawnCandidates
+		i++
+		return v
+	}
+	got = pickSpawnPoint(world, []mgl32.Vec2{{0, 0}}, nil, rnd)
+	if got != (mgl32.Vec2{500, 500}) {
+		t.Errorf("wrapping: got %v, want the candidate furthest from the ship the short way round", got)
+	}
+}
+
+func TestShipProtected(t *testing.T) {
+	now := time.Now()
+	s := ShipComponent{ProtectedUntil: unixMillis(now.Add(spawnProtection))}
+	if !s.Protected(now) {
+		t.Error("new ship isn't protected")
+	}
+	if s.Protected(now.Add(spawnProtection + time.Millisecond)) {
+		t.Error("ship still protected after it ran out")
+	}
+	if (ShipComponent{}).Protected(now) {
+		t.Error("ship without protection is protected")
+	}
+}
@@ -0,0 +1,65 @@
+package superspatial
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+// ScoreboardSystem shows the leaderboard while the Scoreboard button(Tab) is held, and the team scores whenever we're
+// playing on teams.
+type ScoreboardSystem struct {
+	Text  Text
+	Teams Text
+	Font  *common.Font
+
+	leaderboard LeaderboardComponent
+	dirty       bool
+}
+
+func (ss *ScoreboardSystem) SetLeaderboard(lb LeaderboardComponent) {
+	ss.leaderboard = lb
+	ss.dirty = true
+}
+
+func (*ScoreboardSystem) Remove(ecs.BasicEntity) {}
+func (ss *ScoreboardSystem) Update(dt float32) {
+	ss.Text.RenderComponent.Hidden = !engo.Input.Button("Scoreboard").Down()
+
+	if ss.dirty {
+		ss.Text.RenderComponent.Drawable = common.Text{
+			Font: ss.Font,
+			Text: formatLeaderboard(ss.leaderboard),
+		}
+		ss.Teams.RenderComponent.Drawable = common.Text{
+			Font: ss.Font,
+			Text: formatTeamScores(ss.leaderboard.Teams),
+		}
+		ss.Teams.RenderComponent.Hidden = len(ss.leaderboard.Teams) == 0
+		ss.dirty = false
+	}
+}
+
+func formatLeaderboard(lb LeaderboardComponent) string {
+	var sb strings.Builder
+	sb.WriteString("Name                 Kills  Deaths  Streak\n")
+	for _, e := range lb.Entries {
+		fmt.Fprintf(&sb, "%-20s %5d  %6d  %6d\n", e.Name, e.Kills, e.Deaths, e.Streak)
+	}
+	return sb.String()
+}
+
+func formatTeamScores(teams []TeamScore) string {
+	if len(teams) == 0 {
+		// Fonts can't render an empty string.
+		return " "
+	}
+	scores := []string{}
+	for _, t := range teams {
+		scores = append(scores, fmt.Sprintf("%s %d", TeamName(t.Team), t.Kills))
+	}
+	return strings.Join(scores, "  -  ")
+}
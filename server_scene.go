@@ -32,7 +32,12 @@ type SpatialPumpSystem struct {
 
 func (sps *SpatialPumpSystem) Remove(ecs.BasicEntity) {}
 func (sps *SpatialPumpSystem) Update(dt float32) {
+	if sps.SS.spatial == nil {
+		// Disconnected, the rest of this world is on its way out.
+		return
+	}
 	sps.SS.spatial.Update(dt)
+	sps.SS.expireCommands(time.Now())
 
 	for _, e := range sps.SS.Entities {
 		switch ent := e.(type) {
@@ -75,8 +80,17 @@ type ServerScene struct {
 
 	InCritical   bool
 	OnCreateFunc map[sos.RequestID]func(ID sos.EntityID)
+	// Commands answers command requests sent to us, and tracks the ones we've sent.
+	Commands Commands
 
 	Bounds engo.AABB
+	// Topology is the world topology picked on the command line.  When empty the WORLD_TOPOLOGY worker flag is used.
+	Topology string
+	// World is the shape of the world, including whether its edges wrap around.
+	World World
+	// FriendlyFire lets teammates kill each other, they still don't get credit for it.
+	FriendlyFire bool
+	Match        MatchComponent
 
 	CircleCollisionSystem CircleCollisionSystem
 }
@@ -89,6 +103,19 @@ func angleDist(a float32, b float32) float32 {
 	return phi
 }
 
+// attackScore is how hard a ship hits: its speed, scaled by how closely it is
+// pointing along its velocity.  When two ships collide the higher score wins.
+func attackScore(s ShipComponent) float32 {
+	vAngle := mgl32.RadToDeg(float32(math.Atan2(float64(s.Vel[1]), float64(s.Vel[0]))))
+
+	d := angleDist(s.Angle, vAngle)
+	if d > 30 {
+		d = 30
+	}
+
+	return (30 - d) * s.Vel.Len()
+}
+
 func (*ServerScene) Preload() {}
 func (ss *ServerScene) Setup(u engo.Updater) {
 	w, _ := u.(*ecs.World)
@@ -100,8 +127,12 @@ func (ss *ServerScene) Setup(u engo.Updater) {
 	ss.ECS = map[uint64]interface{}{}
 	ss.Clients = map[sos.EntityID][]sos.EntityID{}
 	ss.OnCreateFunc = map[sos.RequestID]func(ID sos.EntityID){}
+	ss.Commands = Commands{}
 
 	ss.Bounds = worldBounds
+	ss.setupWorld(ss.Bounds)
+	ss.phys.World = &ss.World
+	ss.CircleCollisionSystem.World = &ss.World
 
 	w.AddSystem(&ss.phys)
 	w.AddSystem(&SpatialPumpSystem{ss})
@@ -118,45 +149,73 @@ func (ss *ServerScene) Setup(u engo.Updater) {
 	engo.Mailbox.Listen(CircleCollisionMessage{}.Type(), func(msg engo.Message) {
 		collision, ok := msg.(CircleCollisionMessage)
 		if ok {
+			if ship, obstacle, ok := ss.obstacleCollision(collision); ok {
+				ss.phys.Contact(ship.BasicEntity, obstacle.BasicEntity)
+				return
+			}
 			//log.Printf("Collision: %+v %+v %+v", collision, collision.A.SpaceComponent, collision.B.SpaceComponent)
 			shipA, foundShipA := ss.ECS[collision.A.ID()].(*Ship)
 			shipB, foundShipB := ss.ECS[collision.B.ID()].(*Ship)
 
 			if foundShipA && foundShipB && shipA != shipB {
-				delta := shipA.Ship.Pos.Sub(shipB.Ship.Pos).Len()
+				// Ships never sit inside each other, whoever they are.  The physics pushes them apart by however far
+				// they overlap, and does nothing if they only touch.
+				ss.phys.Contact(shipA.BasicEntity, shipB.BasicEntity)
+
+				delta := ss.World.Distance(shipA.Ship.Pos, shipB.Ship.Pos)
 				// Too far away, not a real hit
 				if delta > 64 {
 					return
 				}
 				//log.Printf("---------- %d HIT %d --------: %+v", collision.A.ID(), collision.B.ID(), delta)
-
-				vAngleA := mgl32.RadToDeg(float32(math.Atan2(float64(shipA.Ship.Vel[1]), float64(shipA.Ship.Vel[0]))))
-				vAngleB := mgl32.RadToDeg(float32(math.Atan2(float64(shipB.Ship.Vel[1]), float64(shipB.Ship.Vel[0]))))
-
-				dA := angleDist(shipA.Ship.Angle, vAngleA)
-				dB := angleDist(shipB.Ship.Angle, vAngleB)
-
-				if dA > 30 {
-					dA = 30
+				teammates := sameTeam(shipA.Team, shipB.Team)
+				if teammates && !ss.FriendlyFire {
+					return
 				}
-				if dB > 30 {
-					dB = 30
+				// Freshly spawned ships pass straight through everyone.
+				now := time.Now()
+				if shipA.Ship.Protected(now) || shipB.Ship.Protected(now) {
+					return
 				}
 
-				attackA := (30 - dA) * shipA.Ship.Vel.Len()
-				attackB := (30 - dB) * shipB.Ship.Vel.Len()
-				//log.Printf("A: Angle: %f VAngle: %f Delta: %v AttackA: %f", shipA.Ship.Angle, vAngleA, dA, attackA)
-				//log.Printf("B: Angle: %f VAngle: %f Delta: %v AttackB: %f", shipB.Ship.Angle, vAngleB, dB, attackB)
+				attackA := attackScore(shipA.Ship)
+				attackB := attackScore(shipB.Ship)
+				//log.Printf("A: Angle: %f AttackA: %f", shipA.Ship.Angle, attackA)
+				//log.Printf("B: Angle: %f AttackB: %f", shipB.Ship.Angle, attackB)
 
-				var deadShip *Ship
+				var victim, attacker *Ship
 				if attackB < attackA { // A attacks B
-					deadShip = shipB
+					victim, attacker = shipB, shipA
 				} else if attackA < attackB { // B attacks A
-					deadShip = shipA
+					victim, attacker = shipA, shipB
+				}
+				// Nobody wins, or the winner is still recharging from its last hit, they just bounce off each other.
+				if victim == nil || !attacker.Ship.AttackReady(now) {
+					return
+				}
 
+				// Whoever owns each ship sends its new health or cooldown out, the rest of us work it out the same
+				// way in the meantime.
+				victim.Ship.Health -= float32(attacker.AttackDamage)
+				attacker.Ship.AttackReadyAt = unixMillis(now.Add(attackCooldown))
+				var deadShip, killer *Ship
+				if victim.Ship.Health <= 0 {
+					deadShip, killer = victim, attacker
 				}
 
 				if deadShip != nil {
+					// The balancer counts the death when it respawns the dead ship, we only credit the kill.
+					// Kills after the match is over don't count.
+					if killer.HasAuthority && !teammates && ss.Match.State != MatchEnded {
+						killer.Score.Kills++
+						killer.Score.Streak++
+						ss.spatial.UpdateComponent(killer.ID, cidScore, killer.Score)
+
+						ss.Notify(NotificationComponent{Kind: NotifyKill, Subject: shipName(killer), Object: shipName(deadShip)})
+						if streakMilestone(killer.Score.Streak) {
+							ss.Notify(NotificationComponent{Kind: NotifyStreak, Subject: shipName(killer), Count: killer.Score.Streak})
+						}
+					}
 
 					//log.Printf("Ship: %+v, Target: %+v ", shipA.SpaceComponent.Position, shipB.SpaceComponent.Position)
 					w.RemoveEntity(deadShip.BasicEntity)
@@ -194,7 +253,16 @@ func (ss *ServerScene) InBounds(pos mgl32.Vec3) bool {
 func (ServerScene) OnDisconnect(op sos.DisconnectOp) {
 	os.Exit(0)
 }
-func (ServerScene) OnFlagUpdate(op sos.FlagUpdateOp) {}
+func (ss *ServerScene) OnFlagUpdate(op sos.FlagUpdateOp) {
+	if op.Key == "FRIENDLY_FIRE" {
+		ss.FriendlyFire = op.Value == "true"
+		log.Printf("Friendly fire: %v", ss.FriendlyFire)
+	}
+	// A topology picked on the command line wins over the worker flag.
+	if op.Key == "WORLD_TOPOLOGY" && ss.Topology == "" {
+		ss.setTopology(op.Value)
+	}
+}
 func (ServerScene) OnLogMessage(op sos.LogMessageOp) {
 	log.Debugf("Log: %+v", op)
 }
@@ -232,11 +300,20 @@ func (ss *ServerScene) OnAddComponent(op sos.AddComponentOp) {
 	log.Debugf("OnAddComponent: %+v %+v", op, op.Component)
 	switch c := op.Component.(type) {
 	case *ShipComponent:
-		ent := NewShip(mgl32.Vec2{}, "")
-		ent.ID = op.ID
-		ss.Entities[op.ID] = &ent
-		ss.ECS[ent.BasicEntity.ID()] = &ent
-		ss.CircleCollisionSystem.Add(&ent.BasicEntity, &ent.SpaceComponent, ent.Ship.Radius)
+		ss.ship(op.ID).Ship = *c
+	case *ScoreComponent:
+		// Components can show up in any order, so this may be the first we hear of the ship.
+		ss.ship(op.ID).Score = *c
+	case *TeamComponent:
+		ss.ship(op.ID).Team = *c
+	case *PlayerComponent:
+		ss.ship(op.ID).Player = *c
+	case *ShipAppearanceComponent:
+		ss.ship(op.ID).Appearance = *c
+	case *MatchComponent:
+		ss.Match = *c
+	case *ObstacleComponent:
+		ss.addObstacle(op.ID, *c)
 	case *EffectComponent:
 		go func() {
 			time.Sleep(time.Duration(c.Expiry) * time.Millisecond)
@@ -247,6 +324,21 @@ func (ss *ServerScene) OnAddComponent(op sos.AddComponentOp) {
 	}
 }
 
+// ship finds the ship for an entity, creating it the first time one of its components is added.
+func (ss *ServerScene) ship(ID sos.EntityID) *Ship {
+	if ent, ok := ss.Entities[ID].(*Ship); ok {
+		return ent
+	}
+
+	ent := NewShip(mgl32.Vec2{}, "")
+	ent.ID = ID
+	ss.Entities[ID] = &ent
+	ss.ECS[ent.BasicEntity.ID()] = &ent
+	ss.CircleCollisionSystem.Add(&ent.BasicEntity, &ent.SpaceComponent, ent.Ship.Radius)
+	ss.phys.Add(&ent.BasicEntity, ent.body())
+	return &ent
+}
+
 func (ss *ServerScene) OnRemoveComponent(op sos.RemoveComponentOp) {
 	log.Debugf("OnRemoveComponent: %+v", op)
 	if op.CID == cidWorker {
@@ -259,9 +351,12 @@ func (ss *ServerScene) OnRemoveComponent(op sos.RemoveComponentOp) {
 			log.Printf("nota ship: %+v", ent)
 		} else {
 			ss.CircleCollisionSystem.Remove(ent.BasicEntity)
-
+			ss.phys.Remove(ent.BasicEntity)
 		}
 	}
+	if op.CID == cidObstacle {
+		ss.removeObstacle(op.ID)
+	}
 }
 
 func (ss *ServerScene) OnAuthorityChange(op sos.AuthorityChangeOp) {
@@ -287,6 +382,13 @@ func (ss *ServerScene) OnAuthorityChange(op sos.AuthorityChangeOp) {
 }
 
 func (ss *ServerScene) OnComponentUpdate(op sos.ComponentUpdateOp) {
+	if ss.commandUpdate(op) {
+		return
+	}
+	if m, ok := op.Component.(*MatchComponent); ok {
+		ss.setMatch(*m)
+	}
+
 	shipEnt, ok := ss.Entities[op.ID].(*Ship)
 	if ok {
 		switch c := op.Component.(type) {
@@ -295,16 +397,18 @@ func (ss *ServerScene) OnComponentUpdate(op sos.ComponentUpdateOp) {
 		case *PlayerInputComponent:
 			shipEnt := ss.Entities[op.ID].(*Ship)
 			shipEnt.PIC = *c
+		case *ScoreComponent:
+			shipEnt.Score = *c
+		case *TeamComponent:
+			shipEnt.Team = *c
+		case *PlayerComponent:
+			shipEnt.Player = *c
+		case *ShipAppearanceComponent:
+			shipEnt.Appearance = *c
 		}
 	}
 }
 
-func (ServerScene) OnCommandRequest(op sos.CommandRequestOp) {
-	log.Printf("OnCommandRequest: %+v", op)
-}
-func (ServerScene) OnCommandResponse(op sos.CommandResponseOp) {
-	log.Printf("OnCommandResponse: %+v", op)
-}
 func (ss *ServerScene) AllocComponent(ID sos.EntityID, CID sos.ComponentID) (interface{}, error) {
 	switch CID {
 	case cidACL:
@@ -313,6 +417,8 @@ func (ss *ServerScene) AllocComponent(ID sos.EntityID, CID sos.ComponentID) (int
 		return &ImprobablePosition{}, nil
 	case cidWorker:
 		return &ImprobableWorker{}, nil
+	case cidPlayerClient:
+		return &ImprobablePlayerClient{}, nil
 	case cidShip:
 		return &ShipComponent{}, nil
 	case cidGame:
@@ -323,6 +429,42 @@ func (ss *ServerScene) AllocComponent(ID sos.EntityID, CID sos.ComponentID) (int
 		return &WorkerComponent{}, nil
 	case cidEffect:
 		return &EffectComponent{}, nil
+	case cidScore:
+		return &ScoreComponent{}, nil
+	case cidLeaderboard:
+		return &LeaderboardComponent{}, nil
+	case cidPlayer:
+		return &PlayerComponent{}, nil
+	case cidShipAppearance:
+		return &ShipAppearanceComponent{}, nil
+	case cidTeam:
+		return &TeamComponent{}, nil
+	case cidMatch:
+		return &MatchComponent{}, nil
+	case cidNotification:
+		return &NotificationComponent{}, nil
+	case cidChat:
+		return &ChatComponent{}, nil
+	case cidServerWorker:
+		return &ServerWorkerComponent{}, nil
+	case cidBalancer:
+		return &BalancerComponent{}, nil
+	case cidBalancerStandby:
+		return &BalancerStandbyComponent{}, nil
+	case cidGlobalChat:
+		return &GlobalChatComponent{}, nil
+	case cidSession:
+		return &SessionComponent{}, nil
+	case cidSendMessageQueue:
+		return &SendMessageQueue{}, nil
+	case cidSendMessageAcks:
+		return &SendMessageAcks{}, nil
+	case cidRespawnQueue:
+		return &RespawnQueue{}, nil
+	case cidRespawnAcks:
+		return &RespawnAcks{}, nil
+	case cidObstacle:
+		return &ObstacleComponent{}, nil
 	}
 	return nil, fmt.Errorf("Unimplemented")
 }
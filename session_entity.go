@@ -0,0 +1,91 @@
+package superspatial
+
+import (
+	"github.com/ScottBrooks/sos"
+)
+
+// SessionComponent marks a player's session entity, which lives as long as they're connected.  Players send their
+// requests to the balancer as commands on it rather than their ship, since they don't always have one.
+type SessionComponent struct {
+	WorkerID string
+}
+
+// Commands on the Session component, by their index in the schema.
+const (
+	sessionSendMessage = 1
+	sessionRespawn     = 2
+)
+
+type Session struct {
+	ID               sos.EntityID
+	ACL              ImprobableACL      `sos:"50"`
+	Pos              ImprobablePosition `sos:"54"`
+	Meta             ImprobableMetadata `sos:"53"`
+	Interest         ImprobableInterest `sos:"58"`
+	Session          SessionComponent   `sos:"1020"`
+	SendMessageQueue SendMessageQueue   `sos:"1021"`
+	SendMessageAcks  SendMessageAcks    `sos:"1022"`
+	RespawnQueue     RespawnQueue       `sos:"1023"`
+	RespawnAcks      RespawnAcks        `sos:"1024"`
+}
+
+func NewSession(clientWorkerID string) Session {
+	readAttrSet := []WorkerAttributeSet{
+		{[]string{"balancer"}},
+		{[]string{"workerId:" + clientWorkerID}},
+	}
+	readAcl := WorkerRequirementSet{AttributeSet: readAttrSet}
+	writeAcl := map[uint32]WorkerRequirementSet{
+		cidSendMessageQueue: WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"workerId:" + clientWorkerID}}}},
+		cidSendMessageAcks:  WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidRespawnQueue:     WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"workerId:" + clientWorkerID}}}},
+		cidRespawnAcks:      WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidSession:          WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidInterest:         WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidPosition:         WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidACL:              WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+	}
+
+	return Session{
+		ACL:     ImprobableACL{ComponentWriteAcl: writeAcl, ReadAcl: readAcl},
+		Meta:    ImprobableMetadata{Name: "Session"},
+		Session: SessionComponent{WorkerID: clientWorkerID},
+	}
+}
+
+// sessionInterest lets the player see the acks on their session entity ID, once we know what it is.
+func sessionInterest(ID sos.EntityID) ImprobableInterest {
+	self := int64(ID)
+	return ImprobableInterest{
+		Interest: map[uint32]ComponentInterest{
+			cidSendMessageQueue: ComponentInterest{
+				Queries: []QBIQuery{
+					{Constraint: QBIConstraint{EntityIDConstraint: &self}, ResultComponents: []uint32{cidSession, cidSendMessageAcks, cidRespawnAcks}},
+				},
+			},
+		},
+	}
+}
+
+// createSession gives the client workerID a session entity, unless they already have one.
+func (bs *BalancerScene) createSession(workerID string) {
+	if bs.sessionFor(workerID) != 0 {
+		return
+	}
+	ent := NewSession(workerID)
+	ent.ACL, _ = claimACL(ent.ACL, "", bs.ServerScene.WorkerID)
+	reqID := bs.spatial.CreateEntity(ent)
+	bs.OnCreateFunc[reqID] = func(ID sos.EntityID) {
+		bs.sessions[ID] = workerID
+	}
+}
+
+// sessionFor is the session entity of the client workerID, 0 if they don't have one.
+func (bs *BalancerScene) sessionFor(workerID string) sos.EntityID {
+	for ID, w := range bs.sessions {
+		if w == workerID {
+			return ID
+		}
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package superspatial
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ShipHulls are the hull colours players can pick from, the first is the default.
+var ShipHulls = []string{"aqua", "blue", "green", "orange", "red"}
+
+// Names longer than this get cut off so they fit above a ship.
+const maxShipNameLength = 16
+
+type ShipAppearanceComponent struct {
+	Hull string
+	Name string
+}
+
+// ValidHull reports if hull is one of ShipHulls.
+func ValidHull(hull string) bool {
+	for _, h := range ShipHulls {
+		if h == hull {
+			return true
+		}
+	}
+	return false
+}
+
+// HullSprite is the sprite for hull, falling back to the default for anything we don't know.
+func HullSprite(hull string) string {
+	if !ValidHull(hull) {
+		hull = ShipHulls[0]
+	}
+	return "Ships/ship-" + hull + ".png"
+}
+
+// NextHull is the hull after hull in ShipHulls, wrapping around at the end.
+func NextHull(hull string) string {
+	for i, h := range ShipHulls {
+		if h == hull {
+			return ShipHulls[(i+1)%len(ShipHulls)]
+		}
+	}
+	return ShipHulls[0]
+}
+
+// CleanShipName drops anything unprintable from a player picked name, then trims and shortens it.
+func CleanShipName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsPrint(r) {
+			return r
+		}
+		return -1
+	}, name)
+	name = strings.TrimSpace(name)
+	if r := []rune(name); len(r) > maxShipNameLength {
+		name = string(r[:maxShipNameLength])
+	}
+	return name
+}
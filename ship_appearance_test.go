@@ -0,0 +1,20 @@
+package superspatial
+
+import "testing"
+
+func TestCleanShipName(t *testing.T) {
+	var tests = []struct {
+		name string
+		want string
+	}{
+		{"  Stabby ", "Stabby"},
+		{"Bell\a\nLine", "BellLine"},
+		{"AVeryLongShipNameIndeed", "AVeryLongShipNam"},
+		{"\t\n", ""},
+	}
+	for _, tt := range tests {
+		if got := CleanShipName(tt.name); got != tt.want {
+			t.Errorf("CleanShipName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
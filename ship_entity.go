@@ -2,6 +2,7 @@ package superspatial
 
 import (
 	"math"
+	"time"
 
 	"github.com/EngoEngine/ecs"
 	"github.com/EngoEngine/engo"
@@ -15,20 +16,68 @@ type Ship struct {
 	common.SpaceComponent
 	common.CollisionComponent
 
-	ID       sos.EntityID
-	PIC      PlayerInputComponent `sos:"1003"`
-	ACL      ImprobableACL        `sos:"50"`
-	Pos      ImprobablePosition   `sos:"54"`
-	Meta     ImprobableMetadata   `sos:"53"`
-	Interest ImprobableInterest   `sos:"58"`
-	Ship     ShipComponent        `sos:"1000"`
-	Worker   WorkerComponent      `sos:"1005"`
+	ID         sos.EntityID
+	PIC        PlayerInputComponent    `sos:"1003"`
+	ACL        ImprobableACL           `sos:"50"`
+	Pos        ImprobablePosition      `sos:"54"`
+	Meta       ImprobableMetadata      `sos:"53"`
+	Interest   ImprobableInterest      `sos:"58"`
+	Ship       ShipComponent           `sos:"1000"`
+	Worker     WorkerComponent         `sos:"1005"`
+	Score      ScoreComponent          `sos:"1007"`
+	Player     PlayerComponent         `sos:"1009"`
+	Appearance ShipAppearanceComponent `sos:"1010"`
+	Team       TeamComponent           `sos:"1011"`
+	Chat       ChatComponent           `sos:"1014"`
 
 	Mass         float32
 	AttackDamage uint32
 	HasAuthority bool
 }
 
+// How much of the world a player can see around their ship.
+const (
+	shipViewWidth  = 1024 * 1.5
+	shipViewHeight = 768 * 1.5
+)
+
+// shipInterest is what a player's client gets to see: everything near their ship, and across the seam through any
+// seam strips.
+func shipInterest(seam []QBIConstraint) ImprobableInterest {
+	relConstraint := QBIRelativeBoxConstraint{
+		Edge: EdgeLength{X: shipViewWidth, Y: 30000, Z: shipViewHeight},
+	}
+	view := QBIConstraint{RelativeBoxConstraint: &relConstraint}
+	if len(seam) > 0 {
+		view = QBIConstraint{OrConstraint: append([]QBIConstraint{view}, seam...)}
+	}
+
+	playerInputCID := uint32(cidPlayerInput)
+	leaderboardCID := uint32(cidLeaderboard)
+	matchCID := uint32(cidMatch)
+	notificationCID := uint32(cidNotification)
+	globalChatCID := uint32(cidGlobalChat)
+
+	return ImprobableInterest{
+		Interest: map[uint32]ComponentInterest{
+			cidPlayerInput: ComponentInterest{
+				Queries: []QBIQuery{
+					{Constraint: view, ResultComponents: []uint32{cidShip, cidPosition, cidMetadata, cidWorkerBalancer, cidEffect, cidScore, cidPlayer, cidShipAppearance, cidTeam, cidChat, cidObstacle}},
+					{Constraint: QBIConstraint{ComponentIDConstraint: &leaderboardCID}, ResultComponents: []uint32{cidLeaderboard}},
+					{Constraint: QBIConstraint{ComponentIDConstraint: &matchCID}, ResultComponents: []uint32{cidMatch}},
+					{Constraint: QBIConstraint{ComponentIDConstraint: &notificationCID}, ResultComponents: []uint32{cidNotification}},
+					{Constraint: QBIConstraint{ComponentIDConstraint: &globalChatCID}, ResultComponents: []uint32{cidGlobalChat}},
+				},
+			},
+			cidShip: ComponentInterest{
+				Queries: []QBIQuery{
+					{Constraint: QBIConstraint{ComponentIDConstraint: &playerInputCID}, ResultComponents: []uint32{cidPlayerInput}},
+				},
+			},
+		},
+	}
+}
+
 func NewShip(sp mgl32.Vec2, clientWorkerID string) Ship {
 	readAttrSet := []WorkerAttributeSet{
 		{[]string{"position"}},
@@ -38,41 +87,29 @@ func NewShip(sp mgl32.Vec2, clientWorkerID string) Ship {
 	readAcl := WorkerRequirementSet{AttributeSet: readAttrSet}
 	writeAcl := map[uint32]WorkerRequirementSet{
 		cidPlayerInput:    WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"workerId:" + clientWorkerID}}}},
+		cidShipAppearance: WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"workerId:" + clientWorkerID}}}},
 		cidShip:           WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
 		cidInterest:       WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
 		cidPosition:       WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
 		cidACL:            WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
 		cidWorkerBalancer: WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidScore:          WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidPlayer:         WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidTeam:           WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
+		cidChat:           WorkerRequirementSet{[]WorkerAttributeSet{{[]string{"balancer"}}}},
 	}
-	relConstraint := QBIRelativeBoxConstraint{
-		Edge: EdgeLength{X: 1024 * 1.5, Y: 30000, Z: 768 * 1.5},
-	}
-
-	playerInputCID := uint32(cidPlayerInput)
 
 	ship := Ship{
-		Pos:  ImprobablePosition{Coords: Coordinates{float64(sp[0]), 0, float64(sp[1])}},
-		ACL:  ImprobableACL{ComponentWriteAcl: writeAcl, ReadAcl: readAcl},
-		Meta: ImprobableMetadata{Name: "Client"},
-		Interest: ImprobableInterest{
-			Interest: map[uint32]ComponentInterest{
-				cidPlayerInput: ComponentInterest{
-					Queries: []QBIQuery{
-						{Constraint: QBIConstraint{RelativeBoxConstraint: &relConstraint}, ResultComponents: []uint32{cidShip, cidPosition, cidMetadata, cidWorkerBalancer, cidEffect}},
-					},
-				},
-				cidShip: ComponentInterest{
-					Queries: []QBIQuery{
-						{Constraint: QBIConstraint{ComponentIDConstraint: &playerInputCID}, ResultComponents: []uint32{cidPlayerInput}},
-					},
-				},
-			},
-		},
+		Pos:          ImprobablePosition{Coords: Coordinates{float64(sp[0]), 0, float64(sp[1])}},
+		ACL:          ImprobableACL{ComponentWriteAcl: writeAcl, ReadAcl: readAcl},
+		Meta:         ImprobableMetadata{Name: "Client"},
+		Interest:     shipInterest(nil),
 		Mass:         1000.0,
 		AttackDamage: 20,
 		Ship: ShipComponent{
 			Pos:    sp.Vec3(0),
-			Radius: 32,
+			Radius: shipRadius,
+			Health: maxShipHealth,
 		},
 
 		BasicEntity:        ecs.NewBasic(),
@@ -87,59 +124,52 @@ func NewShip(sp mgl32.Vec2, clientWorkerID string) Ship {
 	return ship
 }
 
-func clampToAABB(pos mgl32.Vec3, vel mgl32.Vec3, aabb engo.AABB) (mgl32.Vec3, mgl32.Vec3) {
-	if pos[0] < aabb.Min.X {
-		pos[0] = aabb.Min.X
-		vel[0] *= -1
-	}
-	if pos[1] < aabb.Min.Y {
-		pos[1] = aabb.Min.Y
-		vel[1] *= -1
-	}
-	if pos[0] > aabb.Max.X {
-		pos[0] = aabb.Max.X
-		vel[0] *= -1
-	}
-	if pos[1] > aabb.Max.Y {
-		pos[1] = aabb.Max.Y
-		vel[1] *= -1
-	}
+// How fast a ship turns at full lock, in degrees a second.
+const maxTurnRate = 90.0
 
-	return pos, vel
-}
+// How big a ship is, it's a circle this size for collisions.
+const shipRadius = 32
 
-func (s *Ship) Update(dt float32) {
-	s.UpdatePos(dt)
-}
+// How much of a beating a ship can take, each hit takes the attacker's AttackDamage off it.
+const maxShipHealth = 100
 
-func (s *Ship) UpdatePos(dt float32) {
-	if s.PIC.Forward {
-		angleRad := float64(mgl32.DegToRad(s.Ship.Angle))
-		accel := mgl32.Vec3{float32(math.Cos(angleRad)), float32(math.Sin(angleRad)), 0}
-		accel = accel.Mul(s.Mass).Mul(dt)
-		s.Ship.Vel = s.Ship.Vel.Add(accel)
+// How long a ship has to wait after landing a hit before it can hurt anyone again.
+const attackCooldown = 500 * time.Millisecond
 
+// Ships can't go any faster than this.
+const maxShipSpeed = 500
+
+// How hard a ship accelerates at full throttle, in pixels a second squared.
+const shipThrust = 1000
+
+// body is how PhysicsSystem moves the ship around.
+func (s *Ship) body() PhysicsBody {
+	return PhysicsBody{
+		Kind:   BodyShip,
+		Pos:    &s.Ship.Pos,
+		Vel:    &s.Ship.Vel,
+		Angle:  &s.Ship.Angle,
+		Spin:   &s.Ship.Spin,
+		Radius: s.Ship.Radius,
+		Mass:   s.Mass,
+		Forces: s.applyInput,
 	}
-	if s.PIC.Back {
-		angleRad := float64(mgl32.DegToRad(s.Ship.Angle))
-		accel := mgl32.Vec3{float32(math.Cos(angleRad)), float32(math.Sin(angleRad)), 0}
-		accel = accel.Mul(s.Mass).Mul(dt)
-		s.Ship.Vel = s.Ship.Vel.Sub(accel)
-	}
-	if s.PIC.Left {
-		s.Ship.Angle -= 90.0 * dt
-	}
-	if s.PIC.Right {
-		s.Ship.Angle += 90.0 * dt
-	}
+}
 
-	vLen := s.Ship.Vel.Len()
-	if vLen > 500 || vLen < -500 {
-		s.Ship.Vel = s.Ship.Vel.Normalize().Mul(500)
+// applyInput turns the player's controls into thrust along our nose, and enough torque to hold a turn of maxTurnRate
+// at full lock.
+func (s *Ship) applyInput(b *PhysicsBody) {
+	throttle, turn := s.PIC.Controls()
+	if throttle != 0 {
+		angleRad := float64(mgl32.DegToRad(s.Ship.Angle))
+		nose := mgl32.Vec3{float32(math.Cos(angleRad)), float32(math.Sin(angleRad)), 0}
+		b.AddForce(nose.Mul(shipThrust * s.Mass * throttle))
 	}
+	b.AddTorque(turn * maxTurnRate * bodyKinds[BodyShip].AngularDamping * s.Mass)
+}
 
-	s.Ship.Pos = s.Ship.Pos.Add(s.Ship.Vel.Mul(dt))
-	s.Ship.Pos, s.Ship.Vel = clampToAABB(s.Ship.Pos, s.Ship.Vel, worldBounds)
+// Update copies where PhysicsSystem moved us to everything else that needs to know.
+func (s *Ship) Update(dt float32) {
 	s.Pos.Coords.X = float64(s.Ship.Pos[0])
 	s.Pos.Coords.Z = float64(s.Ship.Pos[1])
 
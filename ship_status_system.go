@@ -0,0 +1,132 @@
+package superspatial
+
+import (
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+// How much the Loading_Bar sprites are shrunk.
+const statusBarScale = 0.25
+
+// Sizes in the Loading_Bar sprites, before they're scaled.
+const (
+	statusCapWidth  = 50
+	statusFillWidth = 890
+	statusBarHeight = 40
+)
+
+// Where the table sits from the bar's label, and the fill inside the table.
+var (
+	statusTableOffset = engo.Point{X: 140, Y: 4}
+	statusFillInset   = engo.Point{X: 5, Y: 1}
+)
+
+// shipStatusAssets are the sprites the status bars are built from.
+var shipStatusAssets = []string{
+	"UI/Upgrade/Health.png",
+	"UI/Upgrade/Speed.png",
+	"UI/Upgrade/Damage.png",
+	"UI/Loading_Bar/Table.png",
+	"UI/Loading_Bar/Loading_Bar_2_1.png",
+	"UI/Loading_Bar/Loading_Bar_2_2.png",
+	"UI/Loading_Bar/Loading_Bar_2_3.png",
+}
+
+// statusBar is a label and a Loading_Bar that fills from left to right.
+type statusBar struct {
+	Label MenuSprite
+	Table MenuSprite
+	// The fill is a left cap, a middle that stretches, and a right cap.
+	Fill   [3]MenuSprite
+	Offset engo.Point
+}
+
+// ShipStatusSystem shows how much health our ship has left, how fast it's going, and how far its attack has
+// recharged.
+type ShipStatusSystem struct {
+	CS *ClientScene
+
+	Health statusBar
+	Speed  statusBar
+	Attack statusBar
+}
+
+func (sss *ShipStatusSystem) Setup() {
+	sss.setupBar(&sss.Health, "UI/Upgrade/Health.png", engo.Point{X: -500, Y: 290})
+	sss.setupBar(&sss.Speed, "UI/Upgrade/Speed.png", engo.Point{X: -500, Y: 320})
+	sss.setupBar(&sss.Attack, "UI/Upgrade/Damage.png", engo.Point{X: -500, Y: 350})
+}
+
+func (sss *ShipStatusSystem) setupBar(bar *statusBar, label string, offset engo.Point) {
+	newSprite := func(file string, scale float32, zIndex float32) MenuSprite {
+		s := MenuSprite{BasicEntity: ecs.NewBasic()}
+		tex, err := common.LoadedSprite(file)
+		if err != nil {
+			log.Printf("Unable to load %s: %v", file, err)
+		} else {
+			s.RenderComponent.Drawable = tex
+		}
+		s.RenderComponent.Scale = engo.Point{X: scale, Y: scale}
+		s.RenderComponent.SetZIndex(zIndex)
+		sss.CS.R.Add(&s.BasicEntity, &s.RenderComponent, &s.SpaceComponent)
+		return s
+	}
+
+	bar.Offset = offset
+	bar.Label = newSprite(label, 0.5, 100)
+	bar.Table = newSprite("UI/Loading_Bar/Table.png", statusBarScale, 100)
+	for i := range bar.Fill {
+		bar.Fill[i] = newSprite("UI/Loading_Bar/Loading_Bar_2_"+string(rune('1'+i))+".png", statusBarScale, 101)
+	}
+
+	sss.CS.HS.Add(&bar.Label.BasicEntity, &bar.Label.RenderComponent, &bar.Label.SpaceComponent, offset)
+	sss.CS.HS.Add(&bar.Table.BasicEntity, &bar.Table.RenderComponent, &bar.Table.SpaceComponent, engo.Point{X: offset.X + statusTableOffset.X, Y: offset.Y + statusTableOffset.Y})
+}
+
+func (*ShipStatusSystem) Remove(ecs.BasicEntity) {}
+func (sss *ShipStatusSystem) Update(dt float32) {
+	if sss.CS.HS.Camera == nil {
+		return
+	}
+
+	var health, speed, attack float32
+	if ship, ok := sss.CS.Ships[sss.CS.PIS.ID]; ok {
+		health = ship.ShipComponent.Health / maxShipHealth
+		speed = ship.ShipComponent.Vel.Len() / maxShipSpeed
+		attack = ship.ShipComponent.AttackCharge(time.Now())
+	}
+
+	sss.fill(&sss.Health, health)
+	sss.fill(&sss.Speed, speed)
+	sss.fill(&sss.Attack, attack)
+}
+
+// fill sets how full bar is, from 0 to 1.
+func (sss *ShipStatusSystem) fill(bar *statusBar, fraction float32) {
+	if fraction > 1 {
+		fraction = 1
+	}
+	scale := statusBarScale * sss.CS.HS.scale()
+
+	// The caps sit just inside the table.
+	pos := sss.CS.HS.ScreenPos(engo.Point{
+		X: bar.Offset.X + statusTableOffset.X + statusFillInset.X,
+		Y: bar.Offset.Y + statusTableOffset.Y + statusFillInset.Y,
+	})
+	widths := []float32{statusCapWidth * scale, statusFillWidth * scale * fraction, statusCapWidth * scale}
+	for i := range bar.Fill {
+		part := &bar.Fill[i]
+		part.RenderComponent.Hidden = fraction <= 0
+		part.RenderComponent.Scale = engo.Point{X: scale, Y: scale}
+		if i == 1 {
+			part.RenderComponent.Scale.X *= fraction
+		}
+		part.SpaceComponent.Position = pos
+		part.SpaceComponent.Width = widths[i]
+		part.SpaceComponent.Height = statusBarHeight * scale
+		pos.X += widths[i]
+	}
+}
@@ -0,0 +1,67 @@
+package superspatial
+
+import (
+	"github.com/ScottBrooks/sos"
+)
+
+// The sos SpatialSystem we build against sends component updates and creates and deletes entities, and that's about
+// it.  What else we need from it is spelled out here, and used whenever the SpatialSystem we're given can do it, so
+// we keep building against the sos there is while picking up the rest as it lands.
+
+// disconnecter closes the connection to the runtime, so we can leave the game without exiting.
+type disconnecter interface {
+	Disconnect()
+}
+
+// eventSender sends events, which everyone who can see the component hears once.  Events are passed by value.
+type eventSender interface {
+	SendEvent(ID sos.EntityID, CID sos.ComponentID, index uint32, event interface{})
+}
+
+// componentEvents is a ComponentUpdateOp we can read the events from, each is the event's type by value.
+type componentEvents interface {
+	Events() []interface{}
+}
+
+// What the runtime's status code is when a command or entity query worked.
+const statusSuccess = 1
+
+// entityQuerier runs entity queries, for a snapshot of what matches a QBIQuery.  The query is encoded the same way
+// as the ones in an Interest component.
+type entityQuerier interface {
+	SendEntityQuery(query interface{}, timeoutMillis uint32) sos.RequestID
+}
+
+// entityQueryResult is an EntityQueryOp we can read.  Entities holds each entity's result components, which are
+// pointers like the ones in an AddComponentOp, and is only set if StatusCode is statusSuccess.
+type entityQueryResult interface {
+	RequestID() sos.RequestID
+	StatusCode() int
+	Message() string
+	Entities() map[sos.EntityID]map[sos.ComponentID]interface{}
+}
+
+// commandSender sends commands, and answers the ones sent to us.  Requests and responses are passed by value.
+type commandSender interface {
+	SendCommandRequest(ID sos.EntityID, CID sos.ComponentID, index uint32, req interface{}, timeoutMillis uint32) sos.RequestID
+	SendCommandResponse(RID sos.RequestID, CID sos.ComponentID, index uint32, resp interface{})
+	SendCommandFailure(RID sos.RequestID, message string)
+}
+
+// commandRequestOp is a CommandRequestOp we can read, Request is the command's request type by value.
+type commandRequestOp interface {
+	RequestID() sos.RequestID
+	EntityID() sos.EntityID
+	ComponentID() sos.ComponentID
+	CommandIndex() uint32
+	Request() interface{}
+}
+
+// commandResponseOp is a CommandResponseOp we can read, Response is the command's response type by value, and only
+// set if StatusCode is statusSuccess.
+type commandResponseOp interface {
+	RequestID() sos.RequestID
+	StatusCode() int
+	Message() string
+	Response() interface{}
+}
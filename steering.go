@@ -0,0 +1,120 @@
+package superspatial
+
+import (
+	"math"
+
+	"github.com/EngoEngine/engo"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// How far ahead(in seconds) we look when predicting where a ship will be.
+	maxLeadTime = 1.5
+	// Below this speed, pursuit assumes we are at least this fast so the lead doesn't explode.
+	minPursuitSpeed = 100
+	// How far ahead(in seconds) we look for walls.
+	wallLookahead = 0.75
+	// How close to a wall we get before we start steering away.
+	wallMargin = 128
+)
+
+// headingAngle is the angle in degrees of v, using the same convention as ShipComponent.Angle.
+func headingAngle(v mgl32.Vec3) float32 {
+	return mgl32.RadToDeg(float32(math.Atan2(float64(v[1]), float64(v[0]))))
+}
+
+// angleDelta is the shortest signed turn in degrees to get from a to b.  Positive means turning right.
+func angleDelta(a float32, b float32) float32 {
+	d := float32(math.Mod(float64(b-a), 360))
+	if d > 180 {
+		d -= 360
+	}
+	if d < -180 {
+		d += 360
+	}
+	return d
+}
+
+func safeNormalize(v mgl32.Vec3) mgl32.Vec3 {
+	if v.Len() == 0 {
+		return v
+	}
+	return v.Normalize()
+}
+
+// seek steers straight at target.
+func seek(pos mgl32.Vec3, target mgl32.Vec3) mgl32.Vec3 {
+	return safeNormalize(target.Sub(pos))
+}
+
+// flee steers straight away from threat.
+func flee(pos mgl32.Vec3, threat mgl32.Vec3) mgl32.Vec3 {
+	return safeNormalize(pos.Sub(threat))
+}
+
+// interceptPoint predicts where target will be by the time we can reach it travelling at speed.
+func interceptPoint(pos mgl32.Vec3, speed float32, target ShipComponent) mgl32.Vec3 {
+	if speed < minPursuitSpeed {
+		speed = minPursuitSpeed
+	}
+	lead := target.Pos.Sub(pos).Len() / speed
+	if lead > maxLeadTime {
+		lead = maxLeadTime
+	}
+	return target.Pos.Add(target.Vel.Mul(lead))
+}
+
+// pursue steers at where target will be, rather than where it is now.
+func pursue(self ShipComponent, target ShipComponent) mgl32.Vec3 {
+	return seek(self.Pos, interceptPoint(self.Pos, self.Vel.Len(), target))
+}
+
+// evade steers away from where threat is going to be.
+func evade(self ShipComponent, threat ShipComponent) mgl32.Vec3 {
+	return flee(self.Pos, interceptPoint(threat.Pos, threat.Vel.Len(), self))
+}
+
+// avoidWalls steers back toward the middle of bounds when our current velocity will take us within margin of an edge.
+func avoidWalls(self ShipComponent, bounds engo.AABB, margin float32) mgl32.Vec3 {
+	ahead := self.Pos.Add(self.Vel.Mul(wallLookahead))
+
+	var steer mgl32.Vec3
+	if ahead[0] < bounds.Min.X+margin {
+		steer[0] = 1
+	}
+	if ahead[0] > bounds.Max.X-margin {
+		steer[0] = -1
+	}
+	if ahead[1] < bounds.Min.Y+margin {
+		steer[1] = 1
+	}
+	if ahead[1] > bounds.Max.Y-margin {
+		steer[1] = -1
+	}
+	return safeNormalize(steer)
+}
+
+// canRam reports if self would win a collision with target right now, using the same rule the server uses, and
+// target is in front of us.
+func canRam(self ShipComponent, target ShipComponent, cone float32) bool {
+	if attackScore(self) <= attackScore(target) {
+		return false
+	}
+	return angleDist(self.Angle, headingAngle(target.Pos.Sub(self.Pos))) < cone
+}
+
+// steerInput turns a desired direction into the button presses needed to follow it.
+func steerInput(self ShipComponent, desired mgl32.Vec3) PlayerInputComponent {
+	var p PlayerInputComponent
+	if desired.Len() == 0 {
+		return p
+	}
+
+	turn := angleDelta(self.Angle, headingAngle(desired))
+	p.Left = turn < -5
+	p.Right = turn > 5
+	// Only thrust when we're roughly facing the right way, otherwise we just fight our own velocity.
+	p.Forward = math.Abs(float64(turn)) < 45
+
+	return p
+}
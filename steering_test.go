@@ -0,0 +1,72 @@
+package superspatial
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/EngoEngine/engo"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestAngleDelta(t *testing.T) {
+	var tests = []struct {
+		a, b  float32
+		delta float32
+	}{
+		{0, 90, 90},
+		{90, 0, -90},
+		{350, 10, 20},
+		{10, 350, -20},
+		{-170, 170, -20},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%f to %f", tt.a, tt.b), func(t *testing.T) {
+			d := angleDelta(tt.a, tt.b)
+			if d != tt.delta {
+				t.Errorf("got %f, want %f", d, tt.delta)
+			}
+		})
+	}
+}
+
+func TestPursueLeadsTarget(t *testing.T) {
+	self := ShipComponent{Pos: mgl32.Vec3{0, 0, 0}, Vel: mgl32.Vec3{200, 0, 0}}
+	target := ShipComponent{Pos: mgl32.Vec3{400, 0, 0}, Vel: mgl32.Vec3{0, 200, 0}}
+
+	dir := pursue(self, target)
+	if dir[1] <= 0 {
+		t.Errorf("expected to aim ahead of a target moving down, got %v", dir)
+	}
+}
+
+func TestAvoidWalls(t *testing.T) {
+	bounds := engo.AABB{Max: engo.Point{X: 1000, Y: 1000}}
+
+	self := ShipComponent{Pos: mgl32.Vec3{500, 500, 0}, Vel: mgl32.Vec3{100, 0, 0}}
+	if steer := avoidWalls(self, bounds, 100); steer.Len() != 0 {
+		t.Errorf("expected no wall avoidance in the middle, got %v", steer)
+	}
+
+	self = ShipComponent{Pos: mgl32.Vec3{950, 500, 0}, Vel: mgl32.Vec3{100, 0, 0}}
+	if steer := avoidWalls(self, bounds, 100); steer[0] >= 0 {
+		t.Errorf("expected to steer away from the right wall, got %v", steer)
+	}
+}
+
+func TestCanRam(t *testing.T) {
+	fast := ShipComponent{Pos: mgl32.Vec3{0, 0, 0}, Vel: mgl32.Vec3{300, 0, 0}, Angle: 0}
+	slow := ShipComponent{Pos: mgl32.Vec3{200, 0, 0}, Vel: mgl32.Vec3{0, 50, 0}, Angle: 0}
+
+	if !canRam(fast, slow, 20) {
+		t.Errorf("fast ship pointing at a slow one should be able to ram")
+	}
+	if canRam(slow, fast, 20) {
+		t.Errorf("slow ship should not be able to ram a fast one")
+	}
+
+	behind := slow
+	behind.Pos = mgl32.Vec3{-200, 0, 0}
+	if canRam(fast, behind, 20) {
+		t.Errorf("should not ram a ship behind us")
+	}
+}
@@ -0,0 +1,93 @@
+package superspatial
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TeamHulls colour each team's ships, team 1 is red, team 2 blue and so on.
+var TeamHulls = []string{"red", "blue", "green", "orange", "aqua"}
+
+// TeamComponent is the team a ship fights for.  Team 0 is no team, everyone is an enemy.
+type TeamComponent struct {
+	Team int32
+}
+
+// GameMode is how the balancer splits players into teams.  Free-for-all has no teams.
+type GameMode struct {
+	Teams int
+}
+
+func (gm GameMode) String() string {
+	if gm.Teams == 0 {
+		return "ffa"
+	}
+	return fmt.Sprintf("teams:%d", gm.Teams)
+}
+
+// ParseGameMode reads a GAME_MODE flag: "ffa", "teams" for two teams, or "teams:N" for N teams.
+func ParseGameMode(value string) (GameMode, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	switch value {
+	case "", "ffa":
+		return GameMode{}, nil
+	case "teams":
+		return GameMode{Teams: 2}, nil
+	}
+
+	if !strings.HasPrefix(value, "teams:") {
+		return GameMode{}, fmt.Errorf("Unknown game mode: %s", value)
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(value, "teams:"))
+	if err != nil {
+		return GameMode{}, err
+	}
+	if n < 2 || n > len(TeamHulls) {
+		return GameMode{}, fmt.Errorf("Team count must be between 2 and %d, got %d", len(TeamHulls), n)
+	}
+	return GameMode{Teams: n}, nil
+}
+
+// TeamHull is the hull colour for team, or "" for no team.
+func TeamHull(team int32) string {
+	if team <= 0 || int(team) > len(TeamHulls) {
+		return ""
+	}
+	return TeamHulls[team-1]
+}
+
+// TeamName is what we call team on screen.
+func TeamName(team int32) string {
+	hull := TeamHull(team)
+	if hull == "" {
+		return fmt.Sprintf("Team %d", team)
+	}
+	return strings.ToUpper(hull[:1]) + hull[1:]
+}
+
+// ShipHull is the hull a ship is drawn with.  Team colours win over what the player picked so teams are easy to
+// tell apart.
+func ShipHull(appearance ShipAppearanceComponent, team TeamComponent) string {
+	if hull := TeamHull(team.Team); hull != "" {
+		return hull
+	}
+	return appearance.Hull
+}
+
+// sameTeam reports if a and b are teammates.  Ships without a team are never teammates.
+func sameTeam(a, b TeamComponent) bool {
+	return a.Team != 0 && a.Team == b.Team
+}
+
+// smallestTeam picks the team with the fewest players, given the player count of teams 1..n.  Ties go to the lowest
+// team.
+func smallestTeam(counts []int) int32 {
+	best := 0
+	for i, c := range counts {
+		if c < counts[best] {
+			best = i
+		}
+	}
+	return int32(best + 1)
+}
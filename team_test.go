@@ -0,0 +1,84 @@
+package superspatial
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ScottBrooks/sos"
+)
+
+func TestParseGameMode(t *testing.T) {
+	var tests = []struct {
+		value string
+		teams int
+		err   bool
+	}{
+		{"", 0, false},
+		{"ffa", 0, false},
+		{"teams", 2, false},
+		{"Teams:3", 3, false},
+		{"teams:5", 5, false},
+		{"teams:1", 0, true},
+		{"teams:6", 0, true},
+		{"teams:x", 0, true},
+		{"capture", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			mode, err := ParseGameMode(tt.value)
+			if (err != nil) != tt.err {
+				t.Fatalf("got err %v, want err %v", err, tt.err)
+			}
+			if mode.Teams != tt.teams {
+				t.Errorf("got %d teams, want %d", mode.Teams, tt.teams)
+			}
+		})
+	}
+}
+
+func TestSmallestTeam(t *testing.T) {
+	var tests = []struct {
+		counts []int
+		team   int32
+	}{
+		{[]int{0, 0}, 1},
+		{[]int{1, 0}, 2},
+		{[]int{2, 2, 1}, 3},
+		{[]int{3, 1, 1}, 2},
+	}
+	for _, tt := range tests {
+		if team := smallestTeam(tt.counts); team != tt.team {
+			t.Errorf("smallestTeam(%v) = %d, want %d", tt.counts, team, tt.team)
+		}
+	}
+}
+
+func TestSameTeam(t *testing.T) {
+	if sameTeam(TeamComponent{}, TeamComponent{}) {
+		t.Errorf("ships without a team shouldn't be teammates")
+	}
+	if !sameTeam(TeamComponent{2}, TeamComponent{2}) {
+		t.Errorf("expected team 2 to be teammates")
+	}
+	if sameTeam(TeamComponent{1}, TeamComponent{2}) {
+		t.Errorf("expected teams 1 and 2 to be enemies")
+	}
+}
+
+func TestBuildTeamScores(t *testing.T) {
+	entities := map[sos.EntityID]*balancedEntity{
+		1: {Client: "a", Team: TeamComponent{1}, Score: ScoreComponent{Kills: 2, Deaths: 1}},
+		2: {Client: "b", Team: TeamComponent{1}, Score: ScoreComponent{Kills: 1}},
+		3: {Client: "c", Team: TeamComponent{2}, Score: ScoreComponent{Kills: 4, Deaths: 3}},
+	}
+
+	scores := buildTeamScores(entities, GameMode{Teams: 3})
+	want := []TeamScore{{Team: 1, Kills: 3, Deaths: 1}, {Team: 2, Kills: 4, Deaths: 3}, {Team: 3}}
+	if !reflect.DeepEqual(scores, want) {
+		t.Errorf("got %+v, want %+v", scores, want)
+	}
+
+	if scores := buildTeamScores(entities, GameMode{}); len(scores) != 0 {
+		t.Errorf("expected no team scores in ffa, got %+v", scores)
+	}
+}
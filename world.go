@@ -0,0 +1,161 @@
+package superspatial
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/EngoEngine/engo"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Topology is what happens at the edge of the world.
+type Topology int
+
+const (
+	// TopologyBounded bounces ships off the edges.
+	TopologyBounded Topology = iota
+	// TopologyWrap joins opposite edges, flying off one side brings you back on the other.
+	TopologyWrap
+)
+
+var topologyNames = map[Topology]string{
+	TopologyBounded: "bounded",
+	TopologyWrap:    "wrap",
+}
+
+func (t Topology) String() string {
+	return topologyNames[t]
+}
+
+// ParseTopology reads the WORLD_TOPOLOGY flag: bounded or wrap.  Empty means bounded.
+func ParseTopology(s string) (Topology, error) {
+	if s == "" {
+		return TopologyBounded, nil
+	}
+	for t, name := range topologyNames {
+		if name == s {
+			return t, nil
+		}
+	}
+	return TopologyBounded, fmt.Errorf("Unknown topology: %s", s)
+}
+
+// World is the space everything flies around in.  Its methods are safe to call on a nil World, which acts like a
+// bounded one.
+type World struct {
+	Bounds   engo.AABB
+	Topology Topology
+}
+
+// Wraps reports if opposite edges of the world are joined.
+func (w *World) Wraps() bool {
+	return w != nil && w.Topology == TopologyWrap && w.Bounds.Max.X > w.Bounds.Min.X && w.Bounds.Max.Y > w.Bounds.Min.Y
+}
+
+// Delta is the shortest way from from to to, which may be across the seam.
+func (w *World) Delta(from mgl32.Vec3, to mgl32.Vec3) mgl32.Vec3 {
+	d := to.Sub(from)
+	if !w.Wraps() {
+		return d
+	}
+	d[0] = wrapOffset(d[0], w.Bounds.Max.X-w.Bounds.Min.X)
+	d[1] = wrapOffset(d[1], w.Bounds.Max.Y-w.Bounds.Min.Y)
+	return d
+}
+
+// Distance is how far apart a and b are, the short way round.
+func (w *World) Distance(a mgl32.Vec3, b mgl32.Vec3) float32 {
+	return w.Delta(a, b).Len()
+}
+
+// Wrap brings a position that has gone off one edge back on at the other.
+func (w *World) Wrap(p mgl32.Vec3) mgl32.Vec3 {
+	if !w.Wraps() {
+		return p
+	}
+	p[0] = wrapInto(p[0], w.Bounds.Min.X, w.Bounds.Max.X-w.Bounds.Min.X)
+	p[1] = wrapInto(p[1], w.Bounds.Min.Y, w.Bounds.Max.Y-w.Bounds.Min.Y)
+	return p
+}
+
+// wrapInto brings v into min to min+size.
+func wrapInto(v float32, min float32, size float32) float32 {
+	m := float32(math.Mod(float64(v-min), float64(size)))
+	if m < 0 {
+		m += size
+	}
+	return min + m
+}
+
+// wrapOffset brings d into -size/2 to size/2.
+func wrapOffset(d float32, size float32) float32 {
+	return d - size*float32(math.Floor(float64(d/size)+0.5))
+}
+
+// seamStrips covers the part of box hanging off the edge of a wrapping world with strips along the opposite edges, so
+// whatever is just across the seam is in view.  Strips run the whole length of the world and are at least width wide,
+// which keeps them the same while box moves about near an edge.
+func (w *World) seamStrips(box engo.AABB, width float32) []QBIConstraint {
+	if !w.Wraps() {
+		return nil
+	}
+	b := w.Bounds
+	strip := func(over float32, size float32) float32 {
+		return float32(math.Min(math.Max(float64(over), float64(width)), float64(size)))
+	}
+
+	var strips []engo.AABB
+	if over := b.Min.X - box.Min.X; over > 0 {
+		strips = append(strips, engo.AABB{Min: engo.Point{X: b.Max.X - strip(over, b.Max.X-b.Min.X), Y: b.Min.Y}, Max: b.Max})
+	}
+	if over := box.Max.X - b.Max.X; over > 0 {
+		strips = append(strips, engo.AABB{Min: b.Min, Max: engo.Point{X: b.Min.X + strip(over, b.Max.X-b.Min.X), Y: b.Max.Y}})
+	}
+	if over := b.Min.Y - box.Min.Y; over > 0 {
+		strips = append(strips, engo.AABB{Min: engo.Point{X: b.Min.X, Y: b.Max.Y - strip(over, b.Max.Y-b.Min.Y)}, Max: b.Max})
+	}
+	if over := box.Max.Y - b.Max.Y; over > 0 {
+		strips = append(strips, engo.AABB{Min: b.Min, Max: engo.Point{X: b.Max.X, Y: b.Min.Y + strip(over, b.Max.Y-b.Min.Y)}})
+	}
+
+	var constraints []QBIConstraint
+	for _, s := range strips {
+		constraints = append(constraints, QBIConstraint{BoxConstraint: &QBIBoxConstraint{
+			Center: Coordinates{X: float64(s.Min.X+s.Max.X) / 2, Y: 0, Z: float64(s.Min.Y+s.Max.Y) / 2},
+			Edge:   EdgeLength{X: float64(s.Max.X - s.Min.X), Y: 10000, Z: float64(s.Max.Y - s.Min.Y)},
+		}})
+	}
+	return constraints
+}
+
+// seamView places things for drawing wherever they're closest to Focus, so a player near the edge of a wrapping world
+// sees what's just across the seam next to them rather than on the far side of the map.
+type seamView struct {
+	World *World
+	Focus mgl32.Vec3
+}
+
+func (sv *seamView) place(p mgl32.Vec3) mgl32.Vec3 {
+	if sv == nil || !sv.World.Wraps() {
+		return p
+	}
+	return sv.Focus.Add(sv.World.Delta(sv.Focus, p))
+}
+
+// setupWorld starts the world off as bounds, with the topology from the command line if there was one.
+func (ss *ServerScene) setupWorld(bounds engo.AABB) {
+	ss.World = World{Bounds: bounds}
+	if ss.Topology != "" {
+		ss.setTopology(ss.Topology)
+	}
+}
+
+func (ss *ServerScene) setTopology(name string) {
+	t, err := ParseTopology(name)
+	if err != nil {
+		log.Printf("Error parsing topology %s: %v", name, err)
+		return
+	}
+	log.Printf("World topology: %s", t)
+	ss.World.Topology = t
+}
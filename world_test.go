@@ -0,0 +1,115 @@
+package superspatial
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var wrapWorld = &World{Bounds: engo.AABB{Max: engo.Point{X: 2048, Y: 1024}}, Topology: TopologyWrap}
+
+func TestParseTopology(t *testing.T) {
+	if topology, err := ParseTopology(""); err != nil || topology != TopologyBounded {
+		t.Errorf("got %v %v for no flag, want bounded", topology, err)
+	}
+	if topology, err := ParseTopology("wrap"); err != nil || topology != TopologyWrap {
+		t.Errorf("got %v %v, want wrap", topology, err)
+	}
+	if _, err := ParseTopology("donut"); err == nil {
+		t.Errorf("expected an error for an unknown topology")
+	}
+}
+
+func TestWorldDelta(t *testing.T) {
+	a, b := mgl32.Vec3{10, 500, 0}, mgl32.Vec3{2038, 1014, 0}
+
+	// The short way round is back across both seams.
+	if d := wrapWorld.Delta(a, b); d != (mgl32.Vec3{-20, -510, 0}) {
+		t.Errorf("got %v, want to go back across the seams", d)
+	}
+	if dist := wrapWorld.Distance(mgl32.Vec3{10, 500, 0}, mgl32.Vec3{2038, 500, 0}); dist != 20 {
+		t.Errorf("got distance %f, want 20", dist)
+	}
+
+	// Bounded worlds, or no world at all, go straight there.
+	var none *World
+	if d := none.Delta(a, b); d != b.Sub(a) {
+		t.Errorf("got %v without a world, want %v", d, b.Sub(a))
+	}
+}
+
+func TestWorldWrap(t *testing.T) {
+	if p := wrapWorld.Wrap(mgl32.Vec3{2058, -10, 5}); p != (mgl32.Vec3{10, 1014, 5}) {
+		t.Errorf("got %v, want to come back on the other side", p)
+	}
+	bounded := &World{Bounds: wrapWorld.Bounds}
+	if p := bounded.Wrap(mgl32.Vec3{2058, -10, 5}); p != (mgl32.Vec3{2058, -10, 5}) {
+		t.Errorf("got %v, bounded worlds shouldn't wrap", p)
+	}
+}
+
+func TestSeamStrips(t *testing.T) {
+	// Well inside the world there's no seam to look across.
+	inside := engo.AABB{Min: engo.Point{X: 500, Y: 300}, Max: engo.Point{X: 900, Y: 700}}
+	if strips := wrapWorld.seamStrips(inside, 0); len(strips) != 0 {
+		t.Errorf("got %d strips, want none", len(strips))
+	}
+
+	// Hanging 100 off the left edge, we need to see the right hand 100 of the world.
+	left := engo.AABB{Min: engo.Point{X: -100, Y: 300}, Max: engo.Point{X: 300, Y: 700}}
+	strips := wrapWorld.seamStrips(left, 0)
+	if len(strips) != 1 {
+		t.Fatalf("got %d strips, want 1", len(strips))
+	}
+	box := boxAABB(*strips[0].BoxConstraint)
+	if box.Min.X != 1948 || box.Max.X != 2048 || box.Min.Y != 0 || box.Max.Y != 1024 {
+		t.Errorf("got strip %+v", box)
+	}
+
+	// A minimum width keeps the strip the same as we move about.
+	strips = wrapWorld.seamStrips(left, 768)
+	if box := boxAABB(*strips[0].BoxConstraint); box.Min.X != 1280 {
+		t.Errorf("got strip %+v, want it 768 wide", box)
+	}
+
+	bounded := &World{Bounds: wrapWorld.Bounds}
+	if strips := bounded.seamStrips(left, 0); strips != nil {
+		t.Errorf("got %d strips in a bounded world, want none", len(strips))
+	}
+}
+
+func TestPhysicsSystemWraps(t *testing.T) {
+	ps := PhysicsSystem{World: wrapWorld}
+	ent := ecs.NewBasic()
+	b := newBody(mgl32.Vec3{2040, 500, 0}, mgl32.Vec3{480, 0, 0}, 1000)
+	ps.Add(&ent, *b)
+
+	ps.Update(1.0 / 30)
+	if b.Pos[0] < 0 || b.Pos[0] > 20 || b.Vel[0] <= 0 {
+		t.Errorf("expected to fly out the right and in the left, got %v %v", *b.Pos, *b.Vel)
+	}
+}
+
+func TestResolveContactAcrossSeam(t *testing.T) {
+	a := newBody(mgl32.Vec3{10, 500, 0}, mgl32.Vec3{-100, 0, 0}, 1000)
+	b := newBody(mgl32.Vec3{2000, 500, 0}, mgl32.Vec3{100, 0, 0}, 1000)
+
+	resolveContact(wrapWorld, a, b)
+
+	if a.Vel[0] <= 0 || b.Vel[0] >= 0 {
+		t.Errorf("expected to bounce off each other across the seam, got %v %v", *a.Vel, *b.Vel)
+	}
+}
+
+func TestSeamViewPlace(t *testing.T) {
+	sv := &seamView{World: wrapWorld, Focus: mgl32.Vec3{20, 500, 0}}
+	if p := sv.place(mgl32.Vec3{2000, 500, 0}); p != (mgl32.Vec3{-48, 500, 0}) {
+		t.Errorf("got %v, want it drawn just off the left of us", p)
+	}
+	sv.World = &World{Bounds: wrapWorld.Bounds}
+	if p := sv.place(mgl32.Vec3{2000, 500, 0}); p != (mgl32.Vec3{2000, 500, 0}) {
+		t.Errorf("got %v, bounded worlds draw things where they are", p)
+	}
+}